@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/vwency/resilient-scatter-gather/internal/backendstub"
+	"github.com/vwency/resilient-scatter-gather/internal/services"
+	pb_permissions "github.com/vwency/resilient-scatter-gather/proto/permissions"
+	pb_user "github.com/vwency/resilient-scatter-gather/proto/user"
+	pb_vector "github.com/vwency/resilient-scatter-gather/proto/vector"
+)
+
+// backend-stub serves the bundled UserService, VectorMemoryService and
+// PermissionsService implementations on the addresses the gateway's default
+// config dials, so the gateway can be run end-to-end locally.
+func main() {
+	userAddr := flag.String("user-addr", "localhost:9091", "UserService listen address")
+	vectorAddr := flag.String("vector-addr", "localhost:9092", "VectorMemoryService listen address")
+	permissionsAddr := flag.String("permissions-addr", "localhost:9093", "PermissionsService listen address")
+	flag.Parse()
+
+	backends := []struct {
+		addr   string
+		server *backendstub.Server
+	}{
+		{*userAddr, backendstub.NewServer(&pb_user.UserService_ServiceDesc, services.NewUserServiceServer())},
+		{*vectorAddr, backendstub.NewServer(&pb_vector.VectorMemoryService_ServiceDesc, services.NewVectorMemoryServiceServer())},
+		{*permissionsAddr, backendstub.NewServer(&pb_permissions.PermissionsService_ServiceDesc, services.NewPermissionsServiceServer())},
+	}
+
+	var wg sync.WaitGroup
+	for _, b := range backends {
+		lis, err := net.Listen("tcp", b.addr)
+		if err != nil {
+			log.Fatalf("Failed to listen on %s: %v", b.addr, err)
+		}
+
+		wg.Add(1)
+		go func(server *backendstub.Server, lis net.Listener) {
+			defer wg.Done()
+			if err := server.Serve(lis); err != nil {
+				log.Printf("gRPC server on %s stopped: %v", lis.Addr(), err)
+			}
+		}(b.server, lis)
+		log.Printf("Serving stub backend on %s", lis.Addr())
+	}
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	<-sigChan
+
+	log.Println("Shutting down stub backends...")
+	for _, b := range backends {
+		b.server.GracefulStop()
+	}
+	wg.Wait()
+}
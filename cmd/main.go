@@ -1,23 +1,45 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/vwency/resilient-scatter-gather/internal/auth"
+	"github.com/vwency/resilient-scatter-gather/internal/breaker"
+	"github.com/vwency/resilient-scatter-gather/internal/cache"
+	"github.com/vwency/resilient-scatter-gather/internal/faults"
 	"github.com/vwency/resilient-scatter-gather/internal/handler"
+	"github.com/vwency/resilient-scatter-gather/internal/health"
+	"github.com/vwency/resilient-scatter-gather/internal/hedge"
+	"github.com/vwency/resilient-scatter-gather/internal/loadshed"
+	"github.com/vwency/resilient-scatter-gather/internal/logging"
+	"github.com/vwency/resilient-scatter-gather/internal/metrics"
+	"github.com/vwency/resilient-scatter-gather/internal/microbatch"
+	"github.com/vwency/resilient-scatter-gather/internal/ratelimit"
+	"github.com/vwency/resilient-scatter-gather/internal/requestid"
+	"github.com/vwency/resilient-scatter-gather/internal/retry"
+	"github.com/vwency/resilient-scatter-gather/internal/scatter"
 	"github.com/vwency/resilient-scatter-gather/internal/services"
+	"github.com/vwency/resilient-scatter-gather/internal/tlsconfig"
+	"github.com/vwency/resilient-scatter-gather/internal/tracing"
 	"github.com/vwency/resilient-scatter-gather/pkg/config"
 	pb_permissions "github.com/vwency/resilient-scatter-gather/proto/permissions"
 	pb_user "github.com/vwency/resilient-scatter-gather/proto/user"
 	pb_vector "github.com/vwency/resilient-scatter-gather/proto/vector"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
 func main() {
@@ -26,47 +48,161 @@ func main() {
 
 	ctx := context.Background()
 
-	userConn, err := grpc.NewClient(
-		cfg.Grpc.UserService,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
+	logFormat, err := logging.ParseFormat(cfg.App.LogFormat)
+	if err != nil {
+		log.Fatalf("Invalid logging config: %v", err)
+	}
+	logLevel, err := logging.ParseLevel(cfg.App.LogLevel)
+	if err != nil {
+		log.Fatalf("Invalid logging config: %v", err)
+	}
+	logger := logging.New(os.Stdout, logFormat, logLevel).With("service", cfg.App.ServiceName)
+	slog.SetDefault(logger)
+
+	traceExporter, err := tracing.ParseExporter(cfg.Tracing.Exporter)
+	if err != nil {
+		log.Fatalf("Invalid tracing config: %v", err)
+	}
+	shutdownTracing, err := tracing.Setup(ctx, tracing.Settings{
+		ServiceName: cfg.App.ServiceName,
+		Exporter:    traceExporter,
+		Endpoint:    cfg.Tracing.Endpoint,
+		Insecure:    cfg.Tracing.Insecure,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		log.Fatalf("Failed to set up tracing: %v", err)
+	}
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(shutdownCtx); err != nil {
+			logger.Error("tracing shutdown failed", "error", err)
+		}
+	}()
+
+	var dialOptions []grpc.DialOption
+
+	var faultInjector *faults.Injector
+	if cfg.Faults.Enabled {
+		faultInjector = newFaultInjector(cfg.Faults.Rules)
+		dialOptions = append(dialOptions, grpc.WithChainUnaryInterceptor(faultInjector.UnaryClientInterceptor()))
+		logger.Warn("fault injection enabled", "rules", len(cfg.Faults.Rules))
+	}
+
+	watchCtx, stopWatching := context.WithCancel(ctx)
+	defer stopWatching()
+	reloadInterval := cfg.GetTLSReloadInterval()
+
+	userConn, err := grpc.NewClient(cfg.Grpc.UserService,
+		withCredentials(watchCtx, "UserService", cfg.Grpc.TLS.User, reloadInterval, logger, dialOptions)...)
 	if err != nil {
 		log.Fatalf("Failed to connect to UserService: %v", err)
 	}
 	defer userConn.Close()
 
-	vectorConn, err := grpc.NewClient(
-		cfg.Grpc.VectorService,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
+	vectorConn, err := grpc.NewClient(cfg.Grpc.VectorService,
+		withCredentials(watchCtx, "VectorMemoryService", cfg.Grpc.TLS.Vector, reloadInterval, logger, dialOptions)...)
 	if err != nil {
 		log.Fatalf("Failed to connect to VectorMemoryService: %v", err)
 	}
 	defer vectorConn.Close()
 
-	permissionsConn, err := grpc.NewClient(
-		cfg.Grpc.PermissionsService,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
+	permissionsConn, err := grpc.NewClient(cfg.Grpc.PermissionsService,
+		withCredentials(watchCtx, "PermissionsService", cfg.Grpc.TLS.Permissions, reloadInterval, logger, dialOptions)...)
 	if err != nil {
 		log.Fatalf("Failed to connect to PermissionsService: %v", err)
 	}
 	defer permissionsConn.Close()
 
-	userService := services.NewUserServiceClient(
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	gatewayMetrics := metrics.New(registry)
+
+	retryBudget := retry.NewBudget(cfg.Retry.BudgetRatio, cfg.Retry.BudgetMaxTokens)
+
+	userClient := services.NewUserServiceClient(
 		pb_user.NewUserServiceClient(userConn),
-		cfg.GetUserDegradationTimeout(),
+		services.WithLogger(logger),
 	)
+	var userService services.UserService = userClient
+	if cfg.MicroBatching.User.Enabled {
+		userService = services.NewUserServiceBatcher(userClient, newMicroBatchSettings(cfg.MicroBatching.User))
+	}
+	userService = services.NewUserServiceMetrics(userService, gatewayMetrics)
+	if cfg.CircuitBreaker.User.Enabled {
+		userService = services.NewUserServiceBreaker(userService, newCircuitBreaker("UserService", cfg.CircuitBreaker.User))
+	}
+	if cfg.Retry.User.Enabled {
+		userService = services.NewUserServiceRetrier(userService, newRetrier("UserService", cfg.Retry.User, retryBudget))
+	}
+	if cfg.Hedging.User.Enabled {
+		userService = services.NewUserServiceHedger(userService, newHedger("UserService", cfg.Hedging.User))
+	}
+	if cfg.Coalescing.User {
+		userService = services.NewUserServiceCoalescer(userService)
+	}
+	if cfg.Cache.User.Enabled {
+		userService = services.NewUserServiceCache(userService, newCacheSettings(cfg.Cache.User))
+	}
 
-	vectorService := services.NewVectorMemoryServiceClient(
+	vectorClient := services.NewVectorMemoryServiceClient(
 		pb_vector.NewVectorMemoryServiceClient(vectorConn),
-		cfg.GetVectorDegradationTimeout(),
+		services.WithLogger(logger),
 	)
+	var vectorService services.VectorMemoryService = vectorClient
+	if cfg.MicroBatching.Vector.Enabled {
+		vectorService = services.NewVectorMemoryServiceBatcher(vectorClient, newMicroBatchSettings(cfg.MicroBatching.Vector))
+	}
+	vectorService = services.NewVectorMemoryServiceMetrics(vectorService, gatewayMetrics)
+	if cfg.CircuitBreaker.Vector.Enabled {
+		vectorService = services.NewVectorMemoryServiceBreaker(vectorService, newCircuitBreaker("VectorMemoryService", cfg.CircuitBreaker.Vector))
+	}
+	if cfg.Retry.Vector.Enabled {
+		vectorService = services.NewVectorMemoryServiceRetrier(vectorService, newRetrier("VectorMemoryService", cfg.Retry.Vector, retryBudget))
+	}
+	if cfg.Hedging.Vector.Enabled {
+		vectorService = services.NewVectorMemoryServiceHedger(vectorService, newHedger("VectorMemoryService", cfg.Hedging.Vector))
+	}
+	if cfg.Coalescing.Vector {
+		vectorService = services.NewVectorMemoryServiceCoalescer(vectorService)
+	}
 
-	permissionsService := services.NewPermissionsServiceClient(
+	permissionsClient := services.NewPermissionsServiceClient(
 		pb_permissions.NewPermissionsServiceClient(permissionsConn),
-		cfg.GetPermissionsDegradationTimeout(),
+		services.WithLogger(logger),
 	)
+	var permissionsService services.PermissionsService = permissionsClient
+	if cfg.MicroBatching.Permissions.Enabled {
+		permissionsService = services.NewPermissionsServiceBatcher(permissionsClient, newMicroBatchSettings(cfg.MicroBatching.Permissions))
+	}
+	permissionsService = services.NewPermissionsServiceMetrics(permissionsService, gatewayMetrics)
+	if cfg.CircuitBreaker.Permissions.Enabled {
+		permissionsService = services.NewPermissionsServiceBreaker(permissionsService, newCircuitBreaker("PermissionsService", cfg.CircuitBreaker.Permissions))
+	}
+	if cfg.Retry.Permissions.Enabled {
+		permissionsService = services.NewPermissionsServiceRetrier(permissionsService, newRetrier("PermissionsService", cfg.Retry.Permissions, retryBudget))
+	}
+	if cfg.Hedging.Permissions.Enabled {
+		permissionsService = services.NewPermissionsServiceHedger(permissionsService, newHedger("PermissionsService", cfg.Hedging.Permissions))
+	}
+	if cfg.Coalescing.Permissions {
+		permissionsService = services.NewPermissionsServiceCoalescer(permissionsService)
+	}
+	if cfg.Cache.Permissions.Enabled {
+		permissionsService, err = services.NewPermissionsServiceCache(permissionsService, newCacheSettings(cfg.Cache.Permissions))
+		if err != nil {
+			log.Fatalf("Invalid permissions cache config: %v", err)
+		}
+	}
+
+	vectorFallback, err := handler.ParseVectorFallback(cfg.Degradation.VectorFallback)
+	if err != nil {
+		log.Fatalf("Invalid degradation config: %v", err)
+	}
 
 	slaTimeout := time.Duration(cfg.TTL.MaxResponseTimeMs) * time.Millisecond
 	chatSummaryHandler := handler.NewChatSummaryHandler(
@@ -74,46 +210,302 @@ func main() {
 		vectorService,
 		permissionsService,
 		slaTimeout,
+		handler.WithVectorFallback(vectorFallback),
+		handler.WithBudgets(handler.Budgets{
+			EncodingReserve: cfg.GetEncodingReserve(),
+			User:            newBudget(cfg.Degradation.User),
+			Permissions:     newBudget(cfg.Degradation.Permissions),
+			Vector:          newBudget(cfg.Degradation.Vector),
+		}),
+		handler.WithMetrics(gatewayMetrics),
+		handler.WithLogger(logger),
 	)
 
+	readiness := health.NewChecker(cfg.GetHealthTimeout(),
+		newDependency("UserService", true, userConn, cfg.Health.GrpcHealthCheck),
+		newDependency("PermissionsService", true, permissionsConn, cfg.Health.GrpcHealthCheck),
+		newDependency("VectorMemoryService", vectorFallback == handler.VectorFallbackFail, vectorConn, cfg.Health.GrpcHealthCheck),
+	)
+
+	var summaryHandler, batchSummaryHandler http.Handler = chatSummaryHandler, handler.NewBatchChatSummaryHandler(chatSummaryHandler, handler.BatchSettings{
+		MaxItems:    cfg.Batch.MaxItems,
+		Concurrency: cfg.Batch.Concurrency,
+		Timeout:     cfg.GetBatchTimeout(),
+	})
+	// Load shedding sits closest to the handlers so that requests rejected
+	// by authentication or rate limiting do not count as fast completions.
+	if cfg.LoadShedding.Enabled {
+		batchTarget := cfg.GetBatchTimeout()
+		if batchTarget <= 0 {
+			batchTarget = slaTimeout
+		}
+		summaryHandler = newLoadShedder("summary", &cfg, cfg.GetLoadSheddingTarget(), gatewayMetrics).Middleware(summaryHandler)
+		batchSummaryHandler = newLoadShedder("batch_summary", &cfg, batchTarget, gatewayMetrics).Middleware(batchSummaryHandler)
+	}
+	var limiter *ratelimit.Limiter
+	if cfg.RateLimit.Enabled {
+		limiter = ratelimit.New(ratelimit.NewMemoryStore(), ratelimit.Settings{
+			User:              newRate(cfg.RateLimit.User),
+			IP:                newRate(cfg.RateLimit.IP),
+			TrustForwardedFor: cfg.RateLimit.TrustForwardedFor,
+		}, ratelimit.WithMetrics(gatewayMetrics), ratelimit.WithLogger(logger))
+		summaryHandler = limiter.Middleware(summaryHandler)
+		batchSummaryHandler = limiter.Middleware(batchSummaryHandler)
+	}
+	// Authentication wraps the user limit so that it applies per verified
+	// user, and sits behind the address limit so that floods
+	// of bad tokens are limited too.
+	if cfg.Auth.Enabled {
+		verifier := newVerifier(&cfg)
+		summaryHandler = auth.Middleware(verifier, summaryHandler)
+		batchSummaryHandler = auth.Middleware(verifier, batchSummaryHandler)
+	} else {
+		logger.Warn("authentication disabled, trusting user_id sent by callers")
+	}
+	if limiter != nil {
+		summaryHandler = limiter.IPMiddleware(summaryHandler)
+		batchSummaryHandler = limiter.IPMiddleware(batchSummaryHandler)
+	}
+
 	mux := http.NewServeMux()
-	mux.Handle("/api/v1/chat/summary", chatSummaryHandler)
-	mux.HandleFunc("/health", healthCheckHandler)
+	mux.Handle("/api/v1/chat/summary", summaryHandler)
+	mux.Handle("/api/v1/chat/summaries", batchSummaryHandler)
+	mux.Handle("/livez", health.LivenessHandler())
+	mux.Handle("/health", health.LegacyHandler())
+	mux.Handle("/readyz", health.ReadinessHandler(readiness))
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
 
+	// The request ID is resolved outermost, so that responses rejected by
+	// the middleware above carry one too.
 	httpServer := &http.Server{
 		Addr:         fmt.Sprintf(":%s", cfg.App.Port),
-		Handler:      mux,
+		Handler:      requestid.Middleware(mux),
 		ReadTimeout:  5 * time.Second,
 		WriteTimeout: 10 * time.Second,
 		IdleTimeout:  120 * time.Second,
 	}
 
+	// The fault admin endpoint has no authentication, so it gets its own
+	// listener, on loopback unless configured otherwise.
+	var adminServer *http.Server
+	if faultInjector != nil && cfg.Faults.Admin {
+		adminMux := http.NewServeMux()
+		adminMux.Handle("/admin/faults", faults.AdminHandler(faultInjector))
+		adminServer = &http.Server{
+			Addr:         cfg.GetFaultsAdminAddr(),
+			Handler:      adminMux,
+			ReadTimeout:  5 * time.Second,
+			WriteTimeout: 10 * time.Second,
+		}
+		go func() {
+			logger.Warn("fault admin starting", "addr", adminServer.Addr)
+			if err := adminServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Fatalf("Fault admin server failed: %v", err)
+			}
+		}()
+	}
+
 	go func() {
 		sigChan := make(chan os.Signal, 1)
 		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 		<-sigChan
 
-		log.Println("Shutting down server...")
+		logger.Info("shutting down server")
 		shutdownCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
 		defer cancel()
 
 		if err := httpServer.Shutdown(shutdownCtx); err != nil {
-			log.Printf("HTTP server shutdown error: %v", err)
+			logger.Error("HTTP server shutdown failed", "error", err)
+		}
+		if adminServer != nil {
+			_ = adminServer.Shutdown(shutdownCtx)
 		}
 	}()
 
 	addr := fmt.Sprintf(":%s", cfg.App.Port)
-	log.Printf("%s starting on %s (SLA: %dms)", cfg.App.ServiceName, addr, cfg.TTL.MaxResponseTimeMs)
+	logger.Info("server starting", "addr", addr, "sla_ms", cfg.TTL.MaxResponseTimeMs)
 
 	if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		log.Fatalf("HTTP server failed: %v", err)
 	}
 
-	log.Println("Server stopped")
+	logger.Info("server stopped")
+}
+
+func newCircuitBreaker(name string, c config.CircuitBreakerConfig) *breaker.CircuitBreaker {
+	return breaker.New(name, breaker.Settings{
+		FailureRatio:        c.FailureRatio,
+		MinRequests:         c.MinRequests,
+		Window:              c.GetWindow(),
+		CoolDown:            c.GetCoolDown(),
+		HalfOpenMaxRequests: c.HalfOpenMaxRequests,
+	})
+}
+
+func newHedger(name string, c config.HedgingConfig) *hedge.Hedger {
+	return hedge.New(name, hedge.Settings{
+		Percentile:         c.Percentile,
+		InitialDelay:       c.GetInitialDelay(),
+		MinSamples:         c.MinSamples,
+		SampleSize:         c.SampleSize,
+		MaxHedgesPerSecond: c.MaxHedgesPerSecond,
+	})
+}
+
+func newRetrier(name string, c config.RetryConfig, budget *retry.Budget) *retry.Retrier {
+	retryableCodes, err := retry.ParseCodes(c.RetryableCodes)
+	if err != nil {
+		log.Fatalf("Invalid retry config for %s: %v", name, err)
+	}
+
+	return retry.New(name, retry.Policy{
+		MaxAttempts:    c.MaxAttempts,
+		InitialBackoff: c.GetInitialBackoff(),
+		MaxBackoff:     c.GetMaxBackoff(),
+		Multiplier:     c.Multiplier,
+		Jitter:         c.Jitter,
+		MinAttemptTime: c.GetMinAttemptTime(),
+		RetryableCodes: retryableCodes,
+	}, budget)
+}
+
+func newFaultInjector(rules []config.FaultRuleConfig) *faults.Injector {
+	specs := make([]faults.RuleSpec, 0, len(rules))
+	for _, r := range rules {
+		specs = append(specs, faults.RuleSpec{
+			Service:    r.Service,
+			Method:     r.Method,
+			Percentage: r.Percentage,
+			DelayMs:    r.DelayMs,
+			Code:       r.Code,
+			Drop:       r.Drop,
+		})
+	}
+
+	parsed, err := faults.ParseRules(specs)
+	if err != nil {
+		log.Fatalf("Invalid fault injection config: %v", err)
+	}
+	return faults.New(parsed)
+}
+
+func newBudget(c config.BudgetConfig) scatter.Budget {
+	return scatter.Budget{
+		Share:     c.Share,
+		Floor:     c.GetFloor(),
+		Ceiling:   c.GetCeiling(),
+		MinUseful: c.GetMinUseful(),
+	}
+}
+
+func newCacheSettings(c config.CacheConfig) cache.Settings {
+	return cache.Settings{
+		TTL:            c.GetTTL(),
+		StaleTTL:       c.GetStaleTTL(),
+		MaxEntries:     c.MaxEntries,
+		RefreshTimeout: c.GetRefreshTimeout(),
+	}
+}
+
+func newRate(c config.RateLimitConfig) ratelimit.Rate {
+	return ratelimit.Rate{
+		PerSecond: c.RequestsPerSecond,
+		Burst:     c.Burst,
+	}
+}
+
+func newLoadShedder(name string, cfg *config.ServiceConfig, target time.Duration, m *metrics.Metrics) *loadshed.Limiter {
+	return loadshed.New(name, loadshed.Settings{
+		Target:       target,
+		InitialLimit: cfg.LoadShedding.InitialLimit,
+		MinLimit:     cfg.LoadShedding.MinLimit,
+		MaxLimit:     cfg.LoadShedding.MaxLimit,
+		Backoff:      cfg.LoadShedding.Backoff,
+	}, loadshed.WithMetrics(m))
+}
+
+func newVerifier(cfg *config.ServiceConfig) *auth.Verifier {
+	var keys auth.Keys
+	if cfg.Auth.HMACSecretFile != "" {
+		secret, err := os.ReadFile(cfg.Auth.HMACSecretFile)
+		if err != nil {
+			log.Fatalf("Invalid auth config: %v", err)
+		}
+		keys = append(keys, auth.SecretKey(bytes.TrimSpace(secret)))
+	}
+	if cfg.Auth.JWKSFile != "" {
+		set, err := auth.LoadKeySetFile(cfg.Auth.JWKSFile)
+		if err != nil {
+			log.Fatalf("Invalid auth config: %v", err)
+		}
+		keys = append(keys, set)
+	}
+	if cfg.Auth.JWKSURL != "" {
+		keys = append(keys, auth.NewRemoteKeySet(auth.RemoteSettings{
+			URL:     cfg.Auth.JWKSURL,
+			Refresh: cfg.GetJWKSRefresh(),
+		}))
+	}
+	if len(keys) == 0 {
+		log.Fatalf("Invalid auth config: one of hmac_secret_file, jwks_file and jwks_url is required")
+	}
+
+	verifier, err := auth.NewVerifier(auth.Settings{
+		Algorithms: cfg.Auth.Algorithms,
+		Issuer:     cfg.Auth.Issuer,
+		Audience:   cfg.Auth.Audience,
+		Leeway:     cfg.GetAuthLeeway(),
+	}, keys)
+	if err != nil {
+		log.Fatalf("Invalid auth config: %v", err)
+	}
+	return verifier
 }
 
-func healthCheckHandler(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	fmt.Fprintf(w, `{"status":"healthy","timestamp":"%s"}`, time.Now().Format(time.RFC3339))
+// withCredentials returns dialOptions plus the transport credentials of one
+// backend. TLS certificates are reloaded every interval until ctx is done.
+func withCredentials(ctx context.Context, name string, c config.TLSConfig, interval time.Duration, logger *slog.Logger, dialOptions []grpc.DialOption) []grpc.DialOption {
+	opts := append([]grpc.DialOption{}, dialOptions...)
+	if !c.Enabled {
+		return append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	}
+
+	minVersion, err := tlsconfig.ParseVersion(c.MinVersion)
+	if err != nil {
+		log.Fatalf("Invalid TLS config for %s: %v", name, err)
+	}
+	reloader, err := tlsconfig.New(tlsconfig.Settings{
+		CAFile:     c.CAFile,
+		CertFile:   c.CertFile,
+		KeyFile:    c.KeyFile,
+		ServerName: c.ServerName,
+		MinVersion: minVersion,
+	})
+	if err != nil {
+		log.Fatalf("Invalid TLS config for %s: %v", name, err)
+	}
+	if interval > 0 {
+		go reloader.Watch(ctx, interval, logger.With("backend", name))
+	}
+
+	return append(opts, grpc.WithTransportCredentials(reloader.Credentials()))
+}
+
+func newMicroBatchSettings(c config.MicroBatchingConfig) microbatch.Settings {
+	return microbatch.Settings{
+		Window:  c.GetWindow(),
+		MaxSize: c.MaxSize,
+	}
+}
+
+func newDependency(name string, critical bool, conn *grpc.ClientConn, healthCheck bool) health.Dependency {
+	dep := health.Dependency{
+		Name:     name,
+		Critical: critical,
+		Conn:     conn,
+	}
+	if healthCheck {
+		dep.Health = healthpb.NewHealthClient(conn)
+	}
+	return dep
 }
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/vwency/resilient-scatter-gather/internal/backendstub"
+	"github.com/vwency/resilient-scatter-gather/internal/grpccode"
+	"github.com/vwency/resilient-scatter-gather/internal/services"
+	"github.com/vwency/resilient-scatter-gather/internal/simulator"
+	"github.com/vwency/resilient-scatter-gather/pkg/config"
+	pb_permissions "github.com/vwency/resilient-scatter-gather/proto/permissions"
+	pb_user "github.com/vwency/resilient-scatter-gather/proto/user"
+	pb_vector "github.com/vwency/resilient-scatter-gather/proto/vector"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+const outagePollInterval = 100 * time.Millisecond
+
+type backend struct {
+	name     string
+	addr     string
+	server   *backendstub.Server
+	injector *simulator.Injector
+}
+
+// simulator serves the three backends with the latency, error and outage
+// behaviour described in config/simulator, so that degradation scenarios
+// can be reproduced against the real gateway binary.
+func main() {
+	var cfg config.SimulatorConfig
+	config.Init(os.Getenv("APP_ENV"), "simulator", &cfg)
+
+	backends := []*backend{
+		newBackend("UserService", cfg.User, &pb_user.UserService_ServiceDesc, services.NewUserServiceServer()),
+		newBackend("VectorMemoryService", cfg.Vector, &pb_vector.VectorMemoryService_ServiceDesc, services.NewVectorMemoryServiceServer()),
+		newBackend("PermissionsService", cfg.Permissions, &pb_permissions.PermissionsService_ServiceDesc, services.NewPermissionsServiceServer()),
+	}
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	for _, b := range backends {
+		lis, err := net.Listen("tcp", b.addr)
+		if err != nil {
+			log.Fatalf("Failed to listen on %s for %s: %v", b.addr, b.name, err)
+		}
+
+		wg.Add(2)
+		go func(b *backend, lis net.Listener) {
+			defer wg.Done()
+			if err := b.server.Serve(lis); err != nil {
+				log.Printf("%s stopped: %v", b.name, err)
+			}
+		}(b, lis)
+		go func(b *backend) {
+			defer wg.Done()
+			b.reportOutages(done)
+		}(b)
+		log.Printf("Simulating %s on %s", b.name, lis.Addr())
+	}
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	<-sigChan
+
+	log.Println("Shutting down simulator...")
+	close(done)
+	for _, b := range backends {
+		b.server.GracefulStop()
+	}
+	wg.Wait()
+}
+
+func newBackend(name string, c config.SimulatedServiceConfig, desc *grpc.ServiceDesc, impl any) *backend {
+	injector := simulator.New(newSettings(name, c))
+	return &backend{
+		name:     name,
+		addr:     c.Addr,
+		server:   backendstub.NewServer(desc, impl, grpc.UnaryInterceptor(injector.UnaryServerInterceptor())),
+		injector: injector,
+	}
+}
+
+// reportOutages marks the backend NOT_SERVING in its health service while
+// a scripted outage is active.
+func (b *backend) reportOutages(done <-chan struct{}) {
+	ticker := time.NewTicker(outagePollInterval)
+	defer ticker.Stop()
+
+	serving := true
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			_, down := b.injector.ActiveOutage()
+			if wantServing := !down; wantServing == serving {
+				continue
+			}
+			serving = !down
+			b.server.SetServing(serving)
+			log.Printf("%s outage active: %v", b.name, down)
+		}
+	}
+}
+
+func newSettings(name string, c config.SimulatedServiceConfig) simulator.Settings {
+	settings := simulator.Settings{
+		Methods: make(map[string]simulator.Method, len(c.Methods)),
+	}
+
+	for method, m := range c.Methods {
+		distribution, err := simulator.ParseDistribution(m.Distribution)
+		if err != nil {
+			log.Fatalf("Invalid simulator config for %s.%s: %v", name, method, err)
+		}
+		code, err := parseCode(m.ErrorCode)
+		if err != nil {
+			log.Fatalf("Invalid simulator config for %s.%s: %v", name, method, err)
+		}
+
+		settings.Methods[method] = simulator.Method{
+			Latency: simulator.Latency{
+				Distribution: distribution,
+				Mean:         m.GetMean(),
+				StdDev:       m.GetStdDev(),
+				Min:          m.GetMin(),
+				Max:          m.GetMax(),
+			},
+			ErrorRate: m.ErrorRate,
+			ErrorCode: code,
+		}
+	}
+
+	for _, o := range c.Outages {
+		code, err := parseCode(o.Code)
+		if err != nil {
+			log.Fatalf("Invalid simulator outage for %s: %v", name, err)
+		}
+
+		settings.Outages = append(settings.Outages, simulator.Outage{
+			Start:    o.GetStart(),
+			Duration: o.GetDuration(),
+			Every:    o.GetEvery(),
+			Code:     code,
+		})
+	}
+
+	return settings
+}
+
+// parseCode defaults to UNAVAILABLE, the code of a backend that is down.
+func parseCode(name string) (codes.Code, error) {
+	if name == "" {
+		return codes.Unavailable, nil
+	}
+	return grpccode.Parse(name)
+}
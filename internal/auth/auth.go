@@ -0,0 +1,188 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/vwency/resilient-scatter-gather/internal/models"
+	"github.com/vwency/resilient-scatter-gather/internal/requestid"
+)
+
+// Algorithms are the signing algorithms tokens may use.
+var Algorithms = []string{"HS256", "RS256", "ES256"}
+
+// Identity is the verified caller of a request.
+type Identity struct {
+	// Subject is the token's "sub" claim, used as the user ID.
+	Subject string
+}
+
+type contextKey struct{}
+
+func NewContext(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, contextKey{}, identity)
+}
+
+func FromContext(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(contextKey{}).(Identity)
+	return identity, ok && identity.Subject != ""
+}
+
+// Keys tries each source in turn and returns the first key found.
+type Keys []KeySource
+
+func (ks Keys) Key(ctx context.Context, kid, alg string) (any, error) {
+	err := fmt.Errorf("%w: no %s key with kid %q", ErrKeyNotFound, alg, kid)
+	for _, source := range ks {
+		value, sourceErr := source.Key(ctx, kid, alg)
+		if sourceErr == nil {
+			return value, nil
+		}
+		if !errors.Is(sourceErr, ErrKeyNotFound) {
+			err = sourceErr
+		}
+	}
+	return nil, err
+}
+
+// SecretKey returns a key set holding secret as its only, kid-less, HS256
+// key.
+func SecretKey(secret []byte) *KeySet {
+	return &KeySet{keys: []key{{alg: "HS256", value: secret}}}
+}
+
+type Settings struct {
+	// Algorithms restricts the accepted signing algorithms; empty means
+	// all of Algorithms.
+	Algorithms []string
+	// Issuer and Audience, when set, must match the "iss" and "aud"
+	// claims.
+	Issuer   string
+	Audience string
+	// Leeway is the clock skew tolerated on "exp", "nbf" and "iat".
+	Leeway time.Duration
+}
+
+func (s Settings) Validate() error {
+	for _, alg := range s.Algorithms {
+		if !supported(alg) {
+			return fmt.Errorf("unsupported algorithm %q", alg)
+		}
+	}
+	return nil
+}
+
+func supported(alg string) bool {
+	for _, a := range Algorithms {
+		if a == alg {
+			return true
+		}
+	}
+	return false
+}
+
+// Verifier checks bearer tokens. Every token must carry an expiry and a
+// subject.
+type Verifier struct {
+	keys   KeySource
+	parser *jwt.Parser
+}
+
+func NewVerifier(settings Settings, keys KeySource) (*Verifier, error) {
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+	algorithms := settings.Algorithms
+	if len(algorithms) == 0 {
+		algorithms = Algorithms
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(algorithms),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(settings.Leeway),
+	}
+	if settings.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(settings.Issuer))
+	}
+	if settings.Audience != "" {
+		opts = append(opts, jwt.WithAudience(settings.Audience))
+	}
+
+	return &Verifier{
+		keys:   keys,
+		parser: jwt.NewParser(opts...),
+	}, nil
+}
+
+// Verify returns the identity token proves.
+func (v *Verifier) Verify(ctx context.Context, token string) (Identity, error) {
+	var claims jwt.RegisteredClaims
+	_, err := v.parser.ParseWithClaims(token, &claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.keys.Key(ctx, kid, t.Method.Alg())
+	})
+	if err != nil {
+		return Identity{}, err
+	}
+	if claims.Subject == "" {
+		return Identity{}, errors.New("token has no subject")
+	}
+	return Identity{Subject: claims.Subject}, nil
+}
+
+// Middleware rejects requests without a valid bearer token with 401 and
+// passes the others to next with their Identity in the context.
+func Middleware(verifier *Verifier, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := bearerToken(r)
+		if !ok {
+			unauthorized(w, r, "", "bearer token required")
+			return
+		}
+
+		identity, err := verifier.Verify(r.Context(), token)
+		if err != nil {
+			unauthorized(w, r, "invalid_token", err.Error())
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), identity)))
+	})
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// unauthorized answers like the handlers do, plus the WWW-Authenticate
+// challenge of RFC 6750.
+func unauthorized(w http.ResponseWriter, r *http.Request, code, message string) {
+	requestID, _ := requestid.FromContext(r.Context())
+	challenge := "Bearer"
+	if code != "" {
+		challenge += fmt.Sprintf(` error=%q`, code)
+	}
+	w.Header().Set("WWW-Authenticate", challenge)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnauthorized)
+
+	_ = json.NewEncoder(w).Encode(&models.ErrorResponse{
+		Error:     http.StatusText(http.StatusUnauthorized),
+		Code:      http.StatusUnauthorized,
+		Message:   message,
+		RequestID: requestID,
+	})
+}
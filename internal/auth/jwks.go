@@ -0,0 +1,327 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/vwency/resilient-scatter-gather/internal/coalesce"
+)
+
+// KeySource looks up the key that verifies tokens signed with alg by the
+// key kid. An empty kid matches the only key usable for alg.
+type KeySource interface {
+	Key(ctx context.Context, kid, alg string) (any, error)
+}
+
+var ErrKeyNotFound = errors.New("signing key not found")
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	// Symmetric
+	K string `json:"k"`
+}
+
+type key struct {
+	kid string
+	alg string
+	// value is an *rsa.PublicKey, an *ecdsa.PublicKey or a []byte.
+	value any
+}
+
+// KeySet is a parsed JSON Web Key Set.
+type KeySet struct {
+	keys []key
+}
+
+// ParseKeySet parses a JWKS document. Keys of unsupported types or not
+// meant for signatures are skipped.
+func ParseKeySet(data []byte) (*KeySet, error) {
+	return parseKeySet(data, true)
+}
+
+// parseKeySet parses a JWKS document, rejecting symmetric keys unless
+// allowSymmetric is set.
+func parseKeySet(data []byte, allowSymmetric bool) (*KeySet, error) {
+	var doc struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse JWKS: %w", err)
+	}
+
+	set := &KeySet{}
+	for i, k := range doc.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		if k.Kty == "oct" && !allowSymmetric {
+			return nil, fmt.Errorf("JWKS key %d (%q): symmetric keys are not accepted", i, k.Kid)
+		}
+		value, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("JWKS key %d (%q): %w", i, k.Kid, err)
+		}
+		if value == nil {
+			continue
+		}
+		set.keys = append(set.keys, key{kid: k.Kid, alg: k.Alg, value: value})
+	}
+	return set, nil
+}
+
+func LoadKeySetFile(path string) (*KeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseKeySet(data, false)
+}
+
+func (k jwk) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("modulus: %w", err)
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("exponent: %w", err)
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("exponent too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, nil
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("x: %w", err)
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("y: %w", err)
+		}
+		pub := &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+		if _, err := pub.ECDH(); err != nil {
+			return nil, errors.New("point is not on the curve")
+		}
+		return pub, nil
+	case "oct":
+		secret, err := base64.RawURLEncoding.DecodeString(k.K)
+		if err != nil {
+			return nil, fmt.Errorf("k: %w", err)
+		}
+		return secret, nil
+	default:
+		return nil, nil
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, errors.New("empty value")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// usableFor reports whether k can verify alg, going by its key type and
+// its own alg, if it has one.
+func (k key) usableFor(alg string) bool {
+	if k.alg != "" && k.alg != alg {
+		return false
+	}
+	switch k.value.(type) {
+	case *rsa.PublicKey:
+		return alg == "RS256"
+	case *ecdsa.PublicKey:
+		return alg == "ES256"
+	case []byte:
+		return alg == "HS256"
+	}
+	return false
+}
+
+func (s *KeySet) Key(ctx context.Context, kid, alg string) (any, error) {
+	var found *key
+	for i := range s.keys {
+		k := &s.keys[i]
+		if !k.usableFor(alg) || (kid != "" && k.kid != kid) {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("%w: several %s keys match kid %q", ErrKeyNotFound, alg, kid)
+		}
+		found = k
+	}
+	if found == nil {
+		return nil, fmt.Errorf("%w: no %s key with kid %q", ErrKeyNotFound, alg, kid)
+	}
+	return found.value, nil
+}
+
+type RemoteSettings struct {
+	URL string
+	// Refresh is how long a fetched set is used before it is fetched
+	// again. It defaults to five minutes.
+	Refresh time.Duration
+	// MinRefetch is the least time between two fetches, including the
+	// early ones an unknown kid causes. It defaults to ten seconds.
+	MinRefetch time.Duration
+	Client     *http.Client
+}
+
+// RemoteKeySet fetches a JWKS from a URL and caches it. Only the first
+// lookups wait for a fetch; once a set is cached, lookups use it and a
+// set older than Refresh is fetched again in the background, so that a slow
+// or failing issuer never stalls requests. An unknown kid triggers such a
+// fetch early, so that keys the issuer rotated in are found soon after
+// without waiting for Refresh. When a fetch fails the previous set stays
+// in use.
+//
+// Symmetric ("oct") keys are rejected, since they would let whoever serves
+// the set sign tokens.
+type RemoteKeySet struct {
+	settings   RemoteSettings
+	fetches    *coalesce.Group[struct{}, *KeySet]
+	refreshing atomic.Bool
+
+	mu          sync.RWMutex
+	set         *KeySet
+	fetchedAt   time.Time
+	attemptedAt time.Time
+	lastErr     error
+}
+
+func NewRemoteKeySet(settings RemoteSettings) *RemoteKeySet {
+	if settings.Refresh <= 0 {
+		settings.Refresh = 5 * time.Minute
+	}
+	if settings.MinRefetch <= 0 {
+		settings.MinRefetch = 10 * time.Second
+	}
+	if settings.Client == nil {
+		settings.Client = &http.Client{Timeout: 5 * time.Second}
+	}
+	return &RemoteKeySet{
+		settings: settings,
+		fetches:  coalesce.NewGroup[struct{}, *KeySet](),
+	}
+}
+
+func (r *RemoteKeySet) Key(ctx context.Context, kid, alg string) (any, error) {
+	r.mu.RLock()
+	set, stale := r.set, time.Since(r.fetchedAt) >= r.settings.Refresh
+	r.mu.RUnlock()
+
+	switch {
+	case set == nil:
+		fetched, err := r.fetch(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if fetched == nil {
+			return nil, ErrKeyNotFound
+		}
+		set = fetched
+	case stale:
+		r.refresh()
+	}
+
+	value, err := set.Key(ctx, kid, alg)
+	if errors.Is(err, ErrKeyNotFound) {
+		r.refresh()
+	}
+	return value, err
+}
+
+// refresh fetches the set in the background unless a refresh is already
+// running.
+func (r *RemoteKeySet) refresh() {
+	if !r.refreshing.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		defer r.refreshing.Store(false)
+		_, _ = r.fetch(context.Background())
+	}()
+}
+
+// fetch downloads the set unless the last attempt was less than
+// MinRefetch ago, in which case it returns that attempt's outcome.
+func (r *RemoteKeySet) fetch(ctx context.Context) (*KeySet, error) {
+	r.mu.RLock()
+	if !r.attemptedAt.IsZero() && time.Since(r.attemptedAt) < r.settings.MinRefetch {
+		set, err := r.set, r.lastErr
+		r.mu.RUnlock()
+		return set, err
+	}
+	r.mu.RUnlock()
+
+	set, _, err := r.fetches.Do(ctx, struct{}{}, func(ctx context.Context) (*KeySet, error) {
+		set, err := r.download(ctx)
+		if ctx.Err() != nil {
+			// Every caller gave up; that says nothing about the endpoint.
+			return set, err
+		}
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.attemptedAt, r.lastErr = time.Now(), err
+		if err == nil {
+			r.set, r.fetchedAt = set, r.attemptedAt
+		}
+		return set, err
+	})
+	return set, err
+}
+
+func (r *RemoteKeySet) download(ctx context.Context) (*KeySet, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.settings.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.settings.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch JWKS: %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("fetch JWKS: %w", err)
+	}
+	return parseKeySet(data, false)
+}
@@ -0,0 +1,49 @@
+package backendstub
+
+import (
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// Server is a gRPC server for one backend with the standard health service
+// and server reflection registered next to it.
+type Server struct {
+	*grpc.Server
+	health *health.Server
+}
+
+// NewServer creates a server exposing impl as the service described by desc
+// and reports that service, and the server as a whole, as SERVING.
+func NewServer(desc *grpc.ServiceDesc, impl any, opts ...grpc.ServerOption) *Server {
+	s := &Server{
+		Server: grpc.NewServer(opts...),
+		health: health.NewServer(),
+	}
+
+	s.RegisterService(desc, impl)
+	healthpb.RegisterHealthServer(s.Server, s.health)
+	reflection.Register(s.Server)
+
+	s.health.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	s.health.SetServingStatus(desc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+
+	return s
+}
+
+// SetServing flips the health status of every service the server exposes.
+func (s *Server) SetServing(serving bool) {
+	if serving {
+		s.health.Resume()
+	} else {
+		s.health.Shutdown()
+	}
+}
+
+// GracefulStop marks the server NOT_SERVING before draining it, so that
+// health-checking clients stop routing to it first.
+func (s *Server) GracefulStop() {
+	s.health.Shutdown()
+	s.Server.GracefulStop()
+}
@@ -0,0 +1,195 @@
+package breaker
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var ErrOpen = errors.New("circuit breaker is open")
+
+type State int
+
+const (
+	Closed State = iota
+	Open
+	HalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+type Settings struct {
+	// FailureRatio trips the breaker once failures/requests within Window
+	// reaches it.
+	FailureRatio float64
+	// MinRequests is the number of requests a window must contain before
+	// FailureRatio is evaluated.
+	MinRequests int
+	Window      time.Duration
+	// CoolDown is how long the breaker stays open before letting probe
+	// requests through.
+	CoolDown time.Duration
+	// HalfOpenMaxRequests probes must all succeed to close the breaker.
+	HalfOpenMaxRequests int
+}
+
+type CircuitBreaker struct {
+	name     string
+	settings Settings
+
+	mu    sync.Mutex
+	state State
+	// generation changes with every state change and every new closed
+	// window, so that outcomes of calls admitted before it are ignored.
+	generation  uint64
+	requests    int
+	failures    int
+	windowStart time.Time
+	openedAt    time.Time
+	probes      int
+	successes   int
+}
+
+func New(name string, settings Settings) *CircuitBreaker {
+	if settings.MinRequests < 1 {
+		settings.MinRequests = 1
+	}
+	if settings.HalfOpenMaxRequests < 1 {
+		settings.HalfOpenMaxRequests = 1
+	}
+
+	return &CircuitBreaker{
+		name:        name,
+		settings:    settings,
+		windowStart: time.Now(),
+	}
+}
+
+func (cb *CircuitBreaker) Name() string {
+	return cb.name
+}
+
+func (cb *CircuitBreaker) State() State {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.advance(time.Now())
+	return cb.state
+}
+
+// Allow reports whether a call may proceed and returns the generation it
+// was admitted in. Every successful Allow must be followed by exactly one
+// Record with that generation and the outcome of the call, or one Release.
+func (cb *CircuitBreaker) Allow() (uint64, error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.advance(time.Now())
+
+	switch cb.state {
+	case Open:
+		return 0, fmt.Errorf("%s: %w", cb.name, ErrOpen)
+	case HalfOpen:
+		if cb.probes >= cb.settings.HalfOpenMaxRequests {
+			return 0, fmt.Errorf("%s: %w", cb.name, ErrOpen)
+		}
+		cb.probes++
+	}
+
+	return cb.generation, nil
+}
+
+// Record reports the outcome of a call admitted by Allow in generation.
+// Outcomes from an earlier generation are ignored: a call admitted while
+// closed that finishes during half-open says nothing about the probes.
+func (cb *CircuitBreaker) Record(generation uint64, failure bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	now := time.Now()
+	cb.advance(now)
+	if generation != cb.generation {
+		return
+	}
+
+	switch cb.state {
+	case Closed:
+		cb.requests++
+		if failure {
+			cb.failures++
+		}
+		if cb.requests >= cb.settings.MinRequests &&
+			float64(cb.failures)/float64(cb.requests) >= cb.settings.FailureRatio {
+			cb.trip(now)
+		}
+
+	case HalfOpen:
+		if failure {
+			cb.trip(now)
+			return
+		}
+		cb.successes++
+		if cb.successes >= cb.settings.HalfOpenMaxRequests {
+			cb.reset(now)
+		}
+	}
+}
+
+// Release gives back an admission whose call says nothing about the
+// backend, such as one its caller gave up on. It counts as neither success
+// nor failure; in half-open state it frees the probe slot for another call.
+func (cb *CircuitBreaker) Release(generation uint64) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.advance(time.Now())
+	if generation != cb.generation {
+		return
+	}
+
+	if cb.state == HalfOpen && cb.probes > 0 {
+		cb.probes--
+	}
+}
+
+// advance applies the time-based transitions: rolling the closed window
+// over and moving from open to half-open once the cool-down has elapsed.
+func (cb *CircuitBreaker) advance(now time.Time) {
+	switch cb.state {
+	case Closed:
+		if cb.settings.Window > 0 && now.Sub(cb.windowStart) >= cb.settings.Window {
+			cb.requests, cb.failures = 0, 0
+			cb.windowStart = now
+			cb.generation++
+		}
+	case Open:
+		if now.Sub(cb.openedAt) >= cb.settings.CoolDown {
+			cb.state = HalfOpen
+			cb.probes, cb.successes = 0, 0
+			cb.generation++
+		}
+	}
+}
+
+func (cb *CircuitBreaker) trip(now time.Time) {
+	cb.state = Open
+	cb.openedAt = now
+	cb.generation++
+}
+
+func (cb *CircuitBreaker) reset(now time.Time) {
+	cb.state = Closed
+	cb.requests, cb.failures = 0, 0
+	cb.windowStart = now
+	cb.generation++
+}
@@ -0,0 +1,186 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/vwency/resilient-scatter-gather/internal/requestid"
+)
+
+type Settings struct {
+	// TTL is how long an entry is served without revalidation.
+	TTL time.Duration
+	// StaleTTL is how long past TTL an entry may still be served while it
+	// is being revalidated or the backend is failing.
+	StaleTTL   time.Duration
+	MaxEntries int
+	// RefreshTimeout bounds background revalidation calls, which no caller
+	// waits for; it defaults to one second.
+	RefreshTimeout time.Duration
+}
+
+type State int
+
+const (
+	Miss State = iota
+	Fresh
+	Stale
+)
+
+type entry[K comparable, V any] struct {
+	key      K
+	value    V
+	storedAt time.Time
+}
+
+// Cache is an in-process LRU cache with stale-while-revalidate semantics.
+type Cache[K comparable, V any] struct {
+	settings Settings
+
+	mu         sync.Mutex
+	ll         *list.List
+	items      map[K]*list.Element
+	refreshing map[K]struct{}
+}
+
+func New[K comparable, V any](settings Settings) *Cache[K, V] {
+	if settings.RefreshTimeout <= 0 {
+		settings.RefreshTimeout = time.Second
+	}
+	return &Cache[K, V]{
+		settings:   settings,
+		ll:         list.New(),
+		items:      make(map[K]*list.Element),
+		refreshing: make(map[K]struct{}),
+	}
+}
+
+func (c *Cache[K, V]) Lookup(key K) (V, State) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero V
+	el, ok := c.items[key]
+	if !ok {
+		return zero, Miss
+	}
+
+	e := el.Value.(*entry[K, V])
+	age := time.Since(e.storedAt)
+	switch {
+	case age < c.settings.TTL:
+		c.ll.MoveToFront(el)
+		return e.value, Fresh
+	case age < c.settings.TTL+c.settings.StaleTTL:
+		c.ll.MoveToFront(el)
+		return e.value, Stale
+	default:
+		c.removeElement(el)
+		return zero, Miss
+	}
+}
+
+func (c *Cache[K, V]) Store(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		e := el.Value.(*entry[K, V])
+		e.value = value
+		e.storedAt = time.Now()
+		c.ll.MoveToFront(el)
+		return
+	}
+
+	c.items[key] = c.ll.PushFront(&entry[K, V]{key: key, value: value, storedAt: time.Now()})
+
+	if c.settings.MaxEntries > 0 && c.ll.Len() > c.settings.MaxEntries {
+		c.removeElement(c.ll.Back())
+	}
+}
+
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.ll.Len()
+}
+
+func (c *Cache[K, V]) removeElement(el *list.Element) {
+	c.ll.Remove(el)
+	delete(c.items, el.Value.(*entry[K, V]).key)
+}
+
+// startRefresh marks key as being revalidated and reports whether the
+// caller should perform the refresh.
+func (c *Cache[K, V]) startRefresh(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.refreshing[key]; ok {
+		return false
+	}
+	c.refreshing[key] = struct{}{}
+	return true
+}
+
+func (c *Cache[K, V]) endRefresh(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.refreshing, key)
+}
+
+// Fetch returns the cached value for key, calling load on a miss. A stale
+// value is returned immediately and revalidated in the background, so a
+// slow or failing backend keeps being served from cache until StaleTTL
+// runs out.
+func Fetch[K comparable, V any](ctx context.Context, c *Cache[K, V], key K, load func(ctx context.Context) (V, error)) (V, State, error) {
+	value, state := c.Lookup(key)
+	switch state {
+	case Fresh:
+		return value, Fresh, nil
+	case Stale:
+		c.refresh(ctx, key, load)
+		return value, Stale, nil
+	}
+
+	value, err := load(ctx)
+	if err != nil {
+		return value, Miss, err
+	}
+	c.Store(key, value)
+
+	return value, Miss, nil
+}
+
+func (c *Cache[K, V]) refresh(ctx context.Context, key K, load func(ctx context.Context) (V, error)) {
+	if !c.startRefresh(key) {
+		return
+	}
+
+	go func() {
+		defer c.endRefresh(key)
+
+		refreshCtx, cancel := context.WithTimeout(refreshContext(ctx), c.settings.RefreshTimeout)
+		defer cancel()
+
+		if value, err := load(refreshCtx); err == nil {
+			c.Store(key, value)
+		}
+	}()
+}
+
+// refreshContext returns the base context of a background refresh started
+// by a request with ctx. It carries only the request ID: the request's
+// span, log attributes and other values must not describe work that
+// outlives it.
+func refreshContext(ctx context.Context) context.Context {
+	base := context.Background()
+	if id, ok := requestid.FromContext(ctx); ok {
+		base = requestid.NewContext(base, id)
+	}
+	return base
+}
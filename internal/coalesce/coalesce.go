@@ -0,0 +1,192 @@
+package coalesce
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type call[V any] struct {
+	done    chan struct{}
+	value   V
+	err     error
+	waiters int
+	ctx     *callContext
+}
+
+// Group collapses concurrent calls with the same key into one.
+//
+// Unlike x/sync/singleflight, the shared call does not run on the first
+// caller's context: it keeps that caller's values, runs until the latest
+// deadline of its callers and is only cancelled once every waiting caller
+// has given up, so one cancelled or short-lived caller cannot fail the
+// others. Values of callers that join a running call are not seen by it.
+type Group[K comparable, V any] struct {
+	mu    sync.Mutex
+	calls map[K]*call[V]
+}
+
+func NewGroup[K comparable, V any]() *Group[K, V] {
+	return &Group[K, V]{calls: make(map[K]*call[V])}
+}
+
+// Do runs fn once for all concurrent callers using key. shared reports
+// whether the result was produced by a call started by another caller.
+func (g *Group[K, V]) Do(ctx context.Context, key K, fn func(ctx context.Context) (V, error)) (value V, shared bool, err error) {
+	g.mu.Lock()
+	c, ok := g.calls[key]
+	if ok {
+		c.waiters++
+		c.ctx.extend(ctx)
+	} else {
+		c = g.start(ctx, key, fn)
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return c.value, ok, c.err
+	case <-ctx.Done():
+		g.leave(key, c, ctx.Err())
+		var zero V
+		return zero, ok, ctx.Err()
+	}
+}
+
+// start must be called with g.mu held.
+func (g *Group[K, V]) start(ctx context.Context, key K, fn func(ctx context.Context) (V, error)) *call[V] {
+	callCtx := newCallContext(ctx)
+	c := &call[V]{
+		done:    make(chan struct{}),
+		waiters: 1,
+		ctx:     callCtx,
+	}
+	g.calls[key] = c
+
+	go func() {
+		defer callCtx.cancel(context.Canceled)
+
+		value, err := fn(callCtx)
+
+		g.mu.Lock()
+		c.value, c.err = value, err
+		if g.calls[key] == c {
+			delete(g.calls, key)
+		}
+		g.mu.Unlock()
+
+		close(c.done)
+	}()
+
+	return c
+}
+
+// leave drops a caller that gave up with err and cancels the call with it
+// once nobody is waiting for it anymore.
+func (g *Group[K, V]) leave(key K, c *call[V], err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	c.waiters--
+	if c.waiters > 0 {
+		return
+	}
+
+	c.ctx.cancel(err)
+	if g.calls[key] == c {
+		delete(g.calls, key)
+	}
+}
+
+// callContext is the context a shared call runs on. It carries the first
+// caller's values and, unlike a context from context.WithDeadline, has a
+// deadline that moves later as callers with later deadlines join.
+type callContext struct {
+	context.Context
+
+	mu       sync.Mutex
+	deadline time.Time
+	bounded  bool
+	timer    *time.Timer
+	done     chan struct{}
+	err      error
+}
+
+func newCallContext(ctx context.Context) *callContext {
+	c := &callContext{
+		Context: context.WithoutCancel(ctx),
+		done:    make(chan struct{}),
+	}
+	c.deadline, c.bounded = ctx.Deadline()
+	if c.bounded {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.timer = time.AfterFunc(time.Until(c.deadline), c.expire)
+	}
+	return c
+}
+
+// extend pushes the deadline back to that of ctx, or removes it if ctx
+// has none.
+func (c *callContext) extend(ctx context.Context) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.bounded || c.err != nil {
+		return
+	}
+	deadline, ok := ctx.Deadline()
+	switch {
+	case !ok:
+		c.bounded = false
+		c.timer.Stop()
+	case deadline.After(c.deadline):
+		c.deadline = deadline
+		c.timer.Reset(time.Until(deadline))
+	}
+}
+
+func (c *callContext) expire() {
+	c.mu.Lock()
+	bounded, deadline := c.bounded, c.deadline
+	c.mu.Unlock()
+
+	// The timer may fire just as extend moves the deadline.
+	if bounded && !time.Now().Before(deadline) {
+		c.cancel(context.DeadlineExceeded)
+	}
+}
+
+func (c *callContext) cancel(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err != nil {
+		return
+	}
+	c.err = err
+	close(c.done)
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+}
+
+func (c *callContext) Deadline() (time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.deadline, c.bounded
+}
+
+func (c *callContext) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *callContext) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+func (c *callContext) String() string {
+	return "coalesce.callContext"
+}
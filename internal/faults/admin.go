@@ -0,0 +1,47 @@
+package faults
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+type rulesDocument struct {
+	Rules []RuleSpec `json:"rules"`
+}
+
+// AdminHandler exposes the injector's rules: GET lists them, PUT replaces
+// them with the rules in the request body and DELETE removes them all.
+func AdminHandler(injector *Injector) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var doc rulesDocument
+			if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&doc); err != nil {
+				http.Error(w, "invalid rules document: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			rules, err := ParseRules(doc.Rules)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			injector.SetRules(rules)
+		case http.MethodDelete:
+			injector.SetRules(nil)
+		default:
+			w.Header().Set("Allow", "GET, PUT, DELETE")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		rules := injector.Rules()
+		doc := rulesDocument{Rules: make([]RuleSpec, 0, len(rules))}
+		for _, rule := range rules {
+			doc.Rules = append(doc.Rules, rule.Spec())
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(doc)
+	})
+}
@@ -0,0 +1,221 @@
+package faults
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vwency/resilient-scatter-gather/internal/grpccode"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// healthService is left out of rules that do not name it, so that broad
+// rules fail the calls themselves rather than the readiness checks.
+const healthService = "grpc.health.v1.Health"
+
+// maxDropWait bounds how long a dropped call is held for a caller without
+// a deadline, which would otherwise wait forever.
+const maxDropWait = 30 * time.Second
+
+// Rule describes a fault applied to a share of outgoing calls. Empty
+// Service or Method match any service or method, except health checks.
+type Rule struct {
+	// Service is the fully qualified gRPC service, e.g. "user.UserService".
+	Service string
+	Method  string
+	// Percentage of matching calls, between 0 and 100, the rule applies to.
+	Percentage float64
+	// Delay is waited before the call is made or failed.
+	Delay time.Duration
+	// Code fails the call without sending it when it is not OK.
+	Code codes.Code
+	// Drop sends the call but discards the response, so the caller only
+	// sees its own deadline expire, or DeadlineExceeded after 30s when it
+	// has none.
+	Drop bool
+}
+
+func (r Rule) matches(service, method string) bool {
+	if service == healthService && r.Service != healthService {
+		return false
+	}
+	return (r.Service == "" || r.Service == service) && (r.Method == "" || r.Method == method)
+}
+
+// RuleSpec is the wire and config form of a Rule.
+type RuleSpec struct {
+	Service    string  `json:"service,omitempty"`
+	Method     string  `json:"method,omitempty"`
+	Percentage float64 `json:"percentage"`
+	DelayMs    int     `json:"delay_ms,omitempty"`
+	Code       string  `json:"code,omitempty"`
+	Drop       bool    `json:"drop,omitempty"`
+}
+
+func ParseRule(spec RuleSpec) (Rule, error) {
+	if spec.Percentage < 0 || spec.Percentage > 100 {
+		return Rule{}, fmt.Errorf("percentage %v out of range [0, 100]", spec.Percentage)
+	}
+	if spec.DelayMs < 0 {
+		return Rule{}, fmt.Errorf("negative delay %dms", spec.DelayMs)
+	}
+
+	code, err := parseCode(spec.Code)
+	if err != nil {
+		return Rule{}, err
+	}
+	if code != codes.OK && spec.Drop {
+		return Rule{}, fmt.Errorf("a rule cannot both fail with %s and drop the response", code)
+	}
+
+	return Rule{
+		Service:    spec.Service,
+		Method:     spec.Method,
+		Percentage: spec.Percentage,
+		Delay:      time.Duration(spec.DelayMs) * time.Millisecond,
+		Code:       code,
+		Drop:       spec.Drop,
+	}, nil
+}
+
+func ParseRules(specs []RuleSpec) ([]Rule, error) {
+	rules := make([]Rule, 0, len(specs))
+	for i, spec := range specs {
+		rule, err := ParseRule(spec)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func (r Rule) Spec() RuleSpec {
+	spec := RuleSpec{
+		Service:    r.Service,
+		Method:     r.Method,
+		Percentage: r.Percentage,
+		DelayMs:    int(r.Delay / time.Millisecond),
+		Drop:       r.Drop,
+	}
+	if r.Code != codes.OK {
+		spec.Code = r.Code.String()
+	}
+	return spec
+}
+
+// parseCode is grpccode.Parse, except that an empty name means OK.
+func parseCode(name string) (codes.Code, error) {
+	if name == "" {
+		return codes.OK, nil
+	}
+	return grpccode.Parse(name)
+}
+
+// Injector holds the active rules. Rules can be swapped at runtime; calls
+// already in flight keep the rule they were matched against.
+type Injector struct {
+	mu    sync.RWMutex
+	rules []Rule
+
+	rngMu sync.Mutex
+	rng   *rand.Rand
+}
+
+func New(rules []Rule) *Injector {
+	return &Injector{
+		rules: rules,
+		rng:   rand.New(rand.NewPCG(uint64(time.Now().UnixNano()), 0)),
+	}
+}
+
+func (i *Injector) Rules() []Rule {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	rules := make([]Rule, len(i.rules))
+	copy(rules, i.rules)
+	return rules
+}
+
+func (i *Injector) SetRules(rules []Rule) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.rules = rules
+}
+
+// pick returns the first matching rule whose percentage roll hits.
+func (i *Injector) pick(service, method string) (Rule, bool) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	for _, rule := range i.rules {
+		if !rule.matches(service, method) {
+			continue
+		}
+		if i.roll() < rule.Percentage {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+func (i *Injector) roll() float64 {
+	i.rngMu.Lock()
+	defer i.rngMu.Unlock()
+	return i.rng.Float64() * 100
+}
+
+// UnaryClientInterceptor applies the first matching rule to each call.
+func (i *Injector) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, fullMethod string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		service, method := splitMethod(fullMethod)
+		rule, ok := i.pick(service, method)
+		if !ok {
+			return invoker(ctx, fullMethod, req, reply, cc, opts...)
+		}
+
+		if rule.Delay > 0 {
+			timer := time.NewTimer(rule.Delay)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return status.FromContextError(ctx.Err()).Err()
+			}
+		}
+
+		switch {
+		case rule.Code != codes.OK:
+			return status.Errorf(rule.Code, "injected fault on %s", fullMethod)
+		case rule.Drop:
+			// The backend still does the work; only the answer is lost.
+			_ = invoker(ctx, fullMethod, req, reply, cc, opts...)
+			timer := time.NewTimer(maxDropWait)
+			defer timer.Stop()
+			select {
+			case <-ctx.Done():
+				return status.FromContextError(ctx.Err()).Err()
+			case <-timer.C:
+				return status.Errorf(codes.DeadlineExceeded, "injected drop on %s", fullMethod)
+			}
+		default:
+			return invoker(ctx, fullMethod, req, reply, cc, opts...)
+		}
+	}
+}
+
+// splitMethod turns "/user.UserService/GetUser" into its service and
+// method names.
+func splitMethod(fullMethod string) (string, string) {
+	fullMethod = strings.TrimPrefix(fullMethod, "/")
+	if idx := strings.LastIndex(fullMethod, "/"); idx >= 0 {
+		return fullMethod[:idx], fullMethod[idx+1:]
+	}
+	return "", fullMethod
+}
@@ -0,0 +1,24 @@
+package grpccode
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+)
+
+// Parse converts a gRPC code name into its code. It accepts the canonical
+// name, e.g. "RESOURCE_EXHAUSTED", as well as the Go name,
+// "ResourceExhausted", in any case.
+func Parse(name string) (codes.Code, error) {
+	var c codes.Code
+	if err := c.UnmarshalJSON([]byte(`"` + strings.ToUpper(name) + `"`)); err == nil {
+		return c, nil
+	}
+	for c := codes.OK; c <= codes.Unauthenticated; c++ {
+		if strings.EqualFold(c.String(), name) {
+			return c, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid gRPC code %q", name)
+}
@@ -0,0 +1,258 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/vwency/resilient-scatter-gather/internal/loadshed"
+	"github.com/vwency/resilient-scatter-gather/internal/logging"
+	"github.com/vwency/resilient-scatter-gather/internal/models"
+	"github.com/vwency/resilient-scatter-gather/internal/scatter"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	itemStatusOK        = "ok"
+	itemStatusDegraded  = "degraded"
+	itemStatusForbidden = "forbidden"
+	itemStatusError     = "error"
+
+	maxBatchBodyBytes = 1 << 20
+)
+
+type BatchSettings struct {
+	// MaxItems caps the number of chat IDs accepted in one request.
+	MaxItems int
+	// Concurrency is the number of chats fetched at the same time.
+	Concurrency int
+	// Timeout bounds the whole batch. Zero means the summary SLA.
+	Timeout time.Duration
+}
+
+// BatchChatSummaryHandler serves summaries of many chats of one user. The
+// user is fetched once; permissions and context are fetched per chat.
+type BatchChatSummaryHandler struct {
+	summary  *ChatSummaryHandler
+	settings BatchSettings
+}
+
+// NewBatchChatSummaryHandler shares the backends, fallback policy and
+// instrumentation of summary.
+func NewBatchChatSummaryHandler(summary *ChatSummaryHandler, settings BatchSettings) *BatchChatSummaryHandler {
+	if settings.MaxItems < 1 {
+		settings.MaxItems = 50
+	}
+	if settings.Concurrency < 1 {
+		settings.Concurrency = 8
+	}
+	if settings.Timeout <= 0 {
+		settings.Timeout = summary.slaTimeout
+	}
+
+	return &BatchChatSummaryHandler{
+		summary:  summary,
+		settings: settings,
+	}
+}
+
+func (b *BatchChatSummaryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h := b.summary
+	requestStart := time.Now()
+	rec := newStatusRecorder(w)
+	w = rec
+
+	h.metrics.RequestStarted()
+	defer func() {
+		h.metrics.RequestFinished(endpointBatchSummary, rec.status, time.Since(requestStart), b.settings.Timeout)
+	}()
+
+	ctx, span := h.beginRequest(w, r)
+	defer func() {
+		endRequestSpan(span, rec.status)
+	}()
+	defer func() {
+		h.logCompleted(ctx, r, rec.status, time.Since(requestStart))
+	}()
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		h.sendError(ctx, w, "use POST", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.BatchChatSummaryRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBatchBodyBytes)).Decode(&req); err != nil {
+		h.sendError(ctx, w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+	userID, err := requestUser(ctx, req.UserID)
+	if err != nil {
+		h.sendError(ctx, w, err.Error(), http.StatusForbidden)
+		return
+	}
+	req.UserID = userID
+	if err := b.validate(&req); err != nil {
+		h.sendError(ctx, w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx = logging.WithAttrs(ctx, slog.String("user_id", req.UserID), slog.Int("chats", len(req.ChatIDs)))
+	span.SetAttributes(attribute.Int("batch.size", len(req.ChatIDs)))
+
+	ctx, cancel := context.WithTimeout(ctx, b.settings.Timeout)
+	defer cancel()
+
+	g := scatter.New()
+	user := h.registerUser(g, req.UserID)
+
+	gatherCtx, cancelGather := h.gatherContext(ctx)
+	defer cancelGather()
+
+	// The chats are fetched next to the gather rather than as one of its
+	// legs. They run to the same deadline, and a leg racing that deadline
+	// would fail the whole batch instead of timing out chat by chat.
+	chats := make(chan []models.BatchChatSummaryItem, 1)
+	go func() {
+		chats <- b.fetchChats(gatherCtx, req.UserID, req.ChatIDs)
+	}()
+
+	report, err := g.Run(gatherCtx)
+	markMissedDeadline(ctx, report, err)
+	if err != nil {
+		cancelGather()
+		<-chats
+		h.logger.ErrorContext(ctx, "required leg failed", "error", err)
+		h.sendError(ctx, w, fmt.Sprintf("Service unavailable: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	response := &models.BatchChatSummaryResponse{
+		User:      user.Value(),
+		Items:     <-chats,
+		Timestamp: time.Now(),
+	}
+	for _, item := range response.Items {
+		if item.Degraded {
+			response.Degraded = true
+		}
+	}
+	w.Header().Set(headerDegraded, strconv.FormatBool(response.Degraded))
+
+	h.sendJSON(w, response, http.StatusOK)
+}
+
+func (b *BatchChatSummaryHandler) validate(req *models.BatchChatSummaryRequest) error {
+	switch {
+	case req.UserID == "":
+		return errors.New("user_id is required")
+	case len(req.ChatIDs) == 0:
+		return errors.New("chat_ids must not be empty")
+	case len(req.ChatIDs) > b.settings.MaxItems:
+		return fmt.Errorf("at most %d chat_ids are allowed", b.settings.MaxItems)
+	}
+	for _, chatID := range req.ChatIDs {
+		if chatID == "" {
+			return errors.New("chat_ids must not contain empty IDs")
+		}
+	}
+	return nil
+}
+
+// fetchChats summarizes chatIDs with at most Concurrency chats in flight.
+// Chats that could not be started before ctx ended are reported as errors.
+func (b *BatchChatSummaryHandler) fetchChats(ctx context.Context, userID string, chatIDs []string) []models.BatchChatSummaryItem {
+	items := make([]models.BatchChatSummaryItem, len(chatIDs))
+	next := make(chan int)
+
+	workers := min(b.settings.Concurrency, len(chatIDs))
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for range workers {
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				items[i] = b.fetchChat(ctx, userID, chatIDs[i])
+			}
+		}()
+	}
+
+	for i := range chatIDs {
+		if ctx.Err() != nil {
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				loadshed.MarkDropped(ctx)
+			}
+			items[i] = errorItem(chatIDs[i], ctx.Err())
+			continue
+		}
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	return items
+}
+
+func (b *BatchChatSummaryHandler) fetchChat(ctx context.Context, userID, chatID string) models.BatchChatSummaryItem {
+	h := b.summary
+	ctx = logging.WithAttrs(ctx, slog.String("chat_id", chatID))
+
+	g := scatter.New()
+	permissions := h.registerPermissions(g, userID, chatID)
+	vector := h.registerVector(g, chatID)
+
+	report, err := g.Run(ctx)
+	h.logOutcomes(ctx, report)
+	markMissedDeadline(ctx, report, err)
+
+	var denied *accessDeniedError
+	switch {
+	case errors.As(err, &denied):
+		return models.BatchChatSummaryItem{
+			ChatID: chatID,
+			Status: itemStatusForbidden,
+			Code:   http.StatusForbidden,
+			Legs:   legStatuses(report),
+			Error:  denied.Error(),
+		}
+	case err != nil:
+		item := errorItem(chatID, err)
+		item.Legs = legStatuses(report)
+		return item
+	}
+
+	for _, outcome := range report.Legs {
+		if outcome.Err != nil && outcome.Criticality == scatter.Optional {
+			h.metrics.Degraded(outcome.Name)
+		}
+	}
+
+	item := models.BatchChatSummaryItem{
+		ChatID:      chatID,
+		Status:      itemStatusOK,
+		Code:        http.StatusOK,
+		Permissions: permissions.Value(),
+		Context:     h.contextData(vector),
+		Degraded:    report.Degraded,
+		Legs:        legStatuses(report),
+	}
+	if report.Degraded {
+		item.Status = itemStatusDegraded
+	}
+	return item
+}
+
+func errorItem(chatID string, err error) models.BatchChatSummaryItem {
+	return models.BatchChatSummaryItem{
+		ChatID: chatID,
+		Status: itemStatusError,
+		Code:   http.StatusInternalServerError,
+		Error:  fmt.Sprintf("Service unavailable: %v", err),
+	}
+}
@@ -3,23 +3,65 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"time"
 
+	"github.com/vwency/resilient-scatter-gather/internal/auth"
+	"github.com/vwency/resilient-scatter-gather/internal/logging"
+	"github.com/vwency/resilient-scatter-gather/internal/metrics"
 	"github.com/vwency/resilient-scatter-gather/internal/models"
+	"github.com/vwency/resilient-scatter-gather/internal/requestid"
+	"github.com/vwency/resilient-scatter-gather/internal/scatter"
 	"github.com/vwency/resilient-scatter-gather/internal/services"
+	"github.com/vwency/resilient-scatter-gather/internal/tracing"
 	pb_permissions "github.com/vwency/resilient-scatter-gather/proto/permissions"
 	pb_user "github.com/vwency/resilient-scatter-gather/proto/user"
 	pb_vector "github.com/vwency/resilient-scatter-gather/proto/vector"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
+const (
+	// endpointSummary and endpointBatchSummary label the metrics of the two
+	// handlers, matching the names their load shedders report under.
+	endpointSummary      = "summary"
+	endpointBatchSummary = "batch_summary"
+
+	legUser        = "user"
+	legPermissions = "permissions"
+	legVector      = "vector"
+)
+
+// accessDeniedError is returned by the permissions leg when the backend
+// rejects the request, so that the other legs' data is never sent.
+type accessDeniedError struct {
+	reason string
+}
+
+func (e *accessDeniedError) Error() string {
+	if e.reason == "" {
+		return "access denied"
+	}
+	return e.reason
+}
+
 type ChatSummaryHandler struct {
 	userService        services.UserService
 	vectorService      services.VectorMemoryService
 	permissionsService services.PermissionsService
 	slaTimeout         time.Duration
+	vectorFallback     VectorFallback
+	budgets            Budgets
+	metrics            *metrics.Metrics
+	tracer             trace.Tracer
+	logger             *slog.Logger
 }
 
 func NewChatSummaryHandler(
@@ -27,52 +69,88 @@ func NewChatSummaryHandler(
 	vectorService services.VectorMemoryService,
 	permissionsService services.PermissionsService,
 	slaTimeout time.Duration,
+	opts ...Option,
 ) *ChatSummaryHandler {
-	return &ChatSummaryHandler{
+	h := &ChatSummaryHandler{
 		userService:        userService,
 		vectorService:      vectorService,
 		permissionsService: permissionsService,
 		slaTimeout:         slaTimeout,
+		vectorFallback:     VectorFallbackOmit,
+		tracer:             otel.Tracer(tracing.InstrumentationName),
+		logger:             slog.Default(),
 	}
-}
-
-type serviceResult struct {
-	userData        *pb_user.GetUserResponse
-	permissionsData *pb_permissions.CheckAccessResponse
-	contextData     *pb_vector.GetContextResponse
-	err             error
-	serviceName     string
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
 func (h *ChatSummaryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(r.Context(), h.slaTimeout)
+	requestStart := time.Now()
+	rec := newStatusRecorder(w)
+	w = rec
+
+	h.metrics.RequestStarted()
+	defer func() {
+		h.metrics.RequestFinished(endpointSummary, rec.status, time.Since(requestStart), h.slaTimeout)
+	}()
+
+	ctx, span := h.beginRequest(w, r)
+	defer func() {
+		endRequestSpan(span, rec.status)
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, h.slaTimeout)
 	defer cancel()
 
-	userID := r.URL.Query().Get("user_id")
+	userID, userErr := requestUser(ctx, r.URL.Query().Get("user_id"))
 	chatID := r.URL.Query().Get("chat_id")
+	ctx = logging.WithAttrs(ctx, slog.String("user_id", userID), slog.String("chat_id", chatID))
+	defer func() {
+		h.logCompleted(ctx, r, rec.status, time.Since(requestStart))
+	}()
 
+	if userErr != nil {
+		h.sendError(ctx, w, userErr.Error(), http.StatusForbidden)
+		return
+	}
 	if userID == "" || chatID == "" {
-		h.sendError(w, "user_id and chat_id are required", http.StatusBadRequest)
+		h.sendError(ctx, w, "user_id and chat_id are required", http.StatusBadRequest)
 		return
 	}
 
-	start := time.Now()
-	userData, permissionsData, contextData, degraded, err := h.scatterGather(ctx, userID, chatID)
-	elapsed := time.Since(start)
+	userData, permissionsData, contextData, report, err := h.scatterGather(ctx, userID, chatID)
+	span.SetAttributes(attribute.Bool("gateway.degraded", report.Degraded))
+	ctx = logging.WithAttrs(ctx, slog.Bool("degraded", report.Degraded))
+
+	setDegradationHeaders(w, report)
 
-	log.Printf("Request completed in %v (degraded: %v)", elapsed, degraded)
+	var denied *accessDeniedError
+	if errors.As(err, &denied) {
+		h.logger.WarnContext(ctx, "access denied", "reason", denied.reason)
+		h.sendError(ctx, w, denied.Error(), http.StatusForbidden)
+		return
+	}
 
 	if err != nil {
-		log.Printf("Critical service failure: %v", err)
-		h.sendError(w, fmt.Sprintf("Service unavailable: %v", err), http.StatusInternalServerError)
+		h.logger.ErrorContext(ctx, "required leg failed", "error", err)
+		h.sendError(ctx, w, fmt.Sprintf("Service unavailable: %v", err), http.StatusInternalServerError)
 		return
 	}
 
+	for _, outcome := range report.Legs {
+		if outcome.Err != nil && outcome.Criticality == scatter.Optional {
+			h.metrics.Degraded(outcome.Name)
+		}
+	}
+
 	response := &models.ChatSummaryResponse{
 		User:        userData,
 		Permissions: permissionsData,
 		Context:     contextData,
-		Degraded:    degraded,
+		Degraded:    report.Degraded,
+		Legs:        legStatuses(report),
 		Timestamp:   time.Now(),
 	}
 
@@ -83,87 +161,148 @@ func (h *ChatSummaryHandler) scatterGather(ctx context.Context, userID, chatID s
 	*pb_user.GetUserResponse,
 	*pb_permissions.CheckAccessResponse,
 	*pb_vector.GetContextResponse,
-	bool,
+	*scatter.Report,
 	error,
 ) {
-	results := make(chan serviceResult, 3)
-
-	go func() {
-		user, err := h.userService.GetUser(ctx, userID)
-		results <- serviceResult{
-			userData:    user,
-			err:         err,
-			serviceName: "UserService",
-		}
-	}()
+	g := scatter.New()
 
-	go func() {
-		perms, err := h.permissionsService.CheckAccess(ctx, userID, chatID)
-		results <- serviceResult{
-			permissionsData: perms,
-			err:             err,
-			serviceName:     "PermissionsService",
-		}
-	}()
+	user := h.registerUser(g, userID)
+	permissions := h.registerPermissions(g, userID, chatID)
+	vector := h.registerVector(g, chatID)
 
-	go func() {
-		contextData, err := h.vectorService.GetContext(ctx, chatID)
-		results <- serviceResult{
-			contextData: contextData,
-			err:         err,
-			serviceName: "VectorMemoryService",
-		}
-	}()
+	gatherCtx, cancel := h.gatherContext(ctx)
+	defer cancel()
 
-	var (
-		userData        *pb_user.GetUserResponse
-		permissionsData *pb_permissions.CheckAccessResponse
-		contextData     *pb_vector.GetContextResponse
-		degraded        bool
-		received        int
-	)
+	report, err := g.Run(gatherCtx)
+	h.logOutcomes(ctx, report)
+	markMissedDeadline(ctx, report, err)
+	if err != nil {
+		return nil, nil, nil, report, err
+	}
 
-	for received < 3 {
-		select {
-		case result := <-results:
-			received++
-			switch result.serviceName {
-			case "UserService":
-				if result.err != nil {
-					return nil, nil, nil, false, fmt.Errorf("user service failed: %w", result.err)
-				}
-				userData = result.userData
-				log.Printf("✓ UserService succeeded")
-
-			case "PermissionsService":
-				if result.err != nil {
-					return nil, nil, nil, false, fmt.Errorf("permissions service failed: %w", result.err)
-				}
-				permissionsData = result.permissionsData
-				log.Printf("✓ PermissionsService succeeded")
-
-			case "VectorMemoryService":
-				if result.err != nil {
-					log.Printf("⚠ VectorMemoryService failed (degraded): %v", result.err)
-					degraded = true
-					contextData = nil
-				} else {
-					contextData = result.contextData
-					log.Printf("✓ VectorMemoryService succeeded")
-				}
-			}
+	return user.Value(), permissions.Value(), h.contextData(vector), report, nil
+}
 
-		case <-ctx.Done():
-			log.Printf("⚠ Context timeout reached, stopping collection")
-			if userData == nil || permissionsData == nil {
-				return nil, nil, nil, false, fmt.Errorf("critical services timeout")
+// gatherContext ends ctx Budgets.EncodingReserve before its deadline.
+func (h *ChatSummaryHandler) gatherContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	deadline, ok := ctx.Deadline()
+	if !ok || h.budgets.EncodingReserve <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithDeadline(ctx, deadline.Add(-h.budgets.EncodingReserve))
+}
+
+func (h *ChatSummaryHandler) registerUser(g *scatter.Gather, userID string) *scatter.Leg[*pb_user.GetUserResponse] {
+	return scatter.Register(g, scatter.Spec{Name: legUser, Criticality: scatter.Required, Budget: h.budgets.User},
+		func(ctx context.Context) (*pb_user.GetUserResponse, error) {
+			return h.userService.GetUser(ctx, userID)
+		})
+}
+
+// registerPermissions adds the permissions leg, which fails with
+// accessDeniedError when the backend rejects the request.
+func (h *ChatSummaryHandler) registerPermissions(g *scatter.Gather, userID, chatID string) *scatter.Leg[*pb_permissions.CheckAccessResponse] {
+	return scatter.Register(g, scatter.Spec{Name: legPermissions, Criticality: scatter.Required, Budget: h.budgets.Permissions},
+		func(ctx context.Context) (*pb_permissions.CheckAccessResponse, error) {
+			resp, err := h.permissionsService.CheckAccess(ctx, userID, chatID)
+			if err != nil {
+				return nil, err
+			}
+			if !resp.GetAllowed() {
+				return nil, &accessDeniedError{reason: resp.GetReason()}
 			}
-			degraded = true
-			return userData, permissionsData, contextData, degraded, nil
+			return resp, nil
+		})
+}
+
+func (h *ChatSummaryHandler) registerVector(g *scatter.Gather, chatID string) *scatter.Leg[*pb_vector.GetContextResponse] {
+	vectorCriticality := scatter.Optional
+	if h.vectorFallback == VectorFallbackFail {
+		vectorCriticality = scatter.Required
+	}
+
+	return scatter.Register(g, scatter.Spec{Name: legVector, Criticality: vectorCriticality, Budget: h.budgets.Vector},
+		func(ctx context.Context) (*pb_vector.GetContextResponse, error) {
+			return h.vectorService.GetContext(ctx, chatID)
+		})
+}
+
+// contextData applies the vector fallback to a finished vector leg.
+func (h *ChatSummaryHandler) contextData(vector *scatter.Leg[*pb_vector.GetContextResponse]) *pb_vector.GetContextResponse {
+	if vector.Err() != nil && h.vectorFallback == VectorFallbackEmpty {
+		return &pb_vector.GetContextResponse{Items: []*pb_vector.ContextItem{}}
+	}
+	return vector.Value()
+}
+
+func (h *ChatSummaryHandler) logOutcomes(ctx context.Context, report *scatter.Report) {
+	for _, outcome := range report.Legs {
+		switch {
+		case outcome.Err == nil:
+			h.logger.DebugContext(ctx, "leg succeeded",
+				"leg", outcome.Name, "status", outcome.Status, "latency", outcome.Latency)
+		case outcome.Criticality == scatter.Optional:
+			h.logger.WarnContext(ctx, "optional leg failed, degrading response",
+				"leg", outcome.Name, "status", outcome.Status, "latency", outcome.Latency, "error", outcome.Err)
 		}
 	}
+}
+
+var errUserMismatch = errors.New("user_id does not match the authenticated user")
+
+// requestUser returns the user a request acts for. Behind the auth
+// middleware that is the verified subject, and a different user_id is
+// refused; without it, the user_id sent by the caller is trusted.
+func requestUser(ctx context.Context, requested string) (string, error) {
+	identity, ok := auth.FromContext(ctx)
+	if !ok {
+		return requested, nil
+	}
+	if requested != "" && requested != identity.Subject {
+		return "", errUserMismatch
+	}
+	return identity.Subject, nil
+}
+
+// beginRequest starts the server span for r and resolves its request ID,
+// which is echoed in w and attached to the returned context and its logs.
+// An ID already resolved by requestid.Middleware is kept.
+func (h *ChatSummaryHandler) beginRequest(w http.ResponseWriter, r *http.Request) (context.Context, trace.Span) {
+	ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	ctx, span := h.tracer.Start(ctx, r.Method+" "+r.URL.Path,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(r.Method),
+			semconv.URLPath(r.URL.Path),
+		),
+	)
 
-	return userData, permissionsData, contextData, degraded, nil
+	requestID, ok := requestid.FromContext(ctx)
+	if !ok {
+		requestID = requestid.Resolve(r.Header.Get(requestid.Header))
+		ctx = requestid.NewContext(ctx, requestID)
+		w.Header().Set(requestid.Header, requestID)
+	}
+	span.SetAttributes(attribute.String("request.id", requestID))
+
+	return logging.WithAttrs(ctx, slog.String("request_id", requestID)), span
+}
+
+func (h *ChatSummaryHandler) logCompleted(ctx context.Context, r *http.Request, statusCode int, elapsed time.Duration) {
+	h.logger.LogAttrs(ctx, slog.LevelInfo, "request completed",
+		slog.String("method", r.Method),
+		slog.String("path", r.URL.Path),
+		slog.Int("status", statusCode),
+		slog.Duration("duration", elapsed),
+	)
+}
+
+func endRequestSpan(span trace.Span, statusCode int) {
+	span.SetAttributes(semconv.HTTPResponseStatusCode(statusCode))
+	if statusCode >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(statusCode))
+	}
+	span.End()
 }
 
 func (h *ChatSummaryHandler) sendJSON(w http.ResponseWriter, data interface{}, statusCode int) {
@@ -171,15 +310,17 @@ func (h *ChatSummaryHandler) sendJSON(w http.ResponseWriter, data interface{}, s
 	w.WriteHeader(statusCode)
 
 	if err := json.NewEncoder(w).Encode(data); err != nil {
-		log.Printf("Error encoding JSON: %v", err)
+		h.logger.Error("encode response", "error", err)
 	}
 }
 
-func (h *ChatSummaryHandler) sendError(w http.ResponseWriter, message string, statusCode int) {
+func (h *ChatSummaryHandler) sendError(ctx context.Context, w http.ResponseWriter, message string, statusCode int) {
+	requestID, _ := requestid.FromContext(ctx)
 	errResp := &models.ErrorResponse{
-		Error:   http.StatusText(statusCode),
-		Code:    statusCode,
-		Message: message,
+		Error:     http.StatusText(statusCode),
+		Code:      statusCode,
+		Message:   message,
+		RequestID: requestID,
 	}
 	h.sendJSON(w, errResp, statusCode)
 }
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vwency/resilient-scatter-gather/internal/loadshed"
+	"github.com/vwency/resilient-scatter-gather/internal/models"
+	"github.com/vwency/resilient-scatter-gather/internal/scatter"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	headerDegraded  = "X-Degraded"
+	headerLegStatus = "X-Leg-Status"
+)
+
+func legStatuses(report *scatter.Report) map[string]models.LegStatus {
+	legs := make(map[string]models.LegStatus, len(report.Legs))
+	for _, outcome := range report.Legs {
+		legs[outcome.Name] = models.LegStatus{
+			Status:    string(outcome.Status),
+			LatencyMs: float64(outcome.Latency) / float64(time.Millisecond),
+			ErrorCode: errorCode(outcome.Err),
+		}
+	}
+	return legs
+}
+
+// setDegradationHeaders summarizes the report as
+// "X-Leg-Status: user=ok, permissions=cached, vector=timeout".
+func setDegradationHeaders(w http.ResponseWriter, report *scatter.Report) {
+	parts := make([]string, 0, len(report.Legs))
+	for _, outcome := range report.Legs {
+		parts = append(parts, outcome.Name+"="+string(outcome.Status))
+	}
+
+	w.Header().Set(headerDegraded, strconv.FormatBool(report.Degraded))
+	w.Header().Set(headerLegStatus, strings.Join(parts, ", "))
+}
+
+// markMissedDeadline tells the load shedder about a gather that ran out of
+// time. Only those show the gateway is overloaded: fast failures and legs
+// left out by an open breaker say nothing about load.
+func markMissedDeadline(ctx context.Context, report *scatter.Report, err error) {
+	missed := errors.Is(err, context.DeadlineExceeded)
+	for _, outcome := range report.Legs {
+		if outcome.Status == scatter.StatusTimeout || errors.Is(outcome.Err, scatter.ErrBudgetExhausted) {
+			missed = true
+		}
+	}
+	if missed {
+		loadshed.MarkDropped(ctx)
+	}
+}
+
+func errorCode(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var denied *accessDeniedError
+	if errors.As(err, &denied) {
+		return codes.PermissionDenied.String()
+	}
+	if errors.Is(err, scatter.ErrBudgetExhausted) {
+		return codes.DeadlineExceeded.String()
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return status.FromContextError(err).Code().String()
+	}
+
+	return status.Code(err).String()
+}
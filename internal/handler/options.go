@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/vwency/resilient-scatter-gather/internal/metrics"
+	"github.com/vwency/resilient-scatter-gather/internal/scatter"
+	"github.com/vwency/resilient-scatter-gather/internal/tracing"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// VectorFallback decides what a summary contains when the vector leg fails.
+type VectorFallback string
+
+const (
+	// VectorFallbackOmit leaves the context out of a degraded response.
+	VectorFallbackOmit VectorFallback = "omit"
+	// VectorFallbackEmpty returns an empty context in a degraded response.
+	VectorFallbackEmpty VectorFallback = "empty"
+	// VectorFallbackFail treats the vector leg as required.
+	VectorFallbackFail VectorFallback = "fail"
+)
+
+func ParseVectorFallback(s string) (VectorFallback, error) {
+	switch f := VectorFallback(s); f {
+	case "":
+		return VectorFallbackOmit, nil
+	case VectorFallbackOmit, VectorFallbackEmpty, VectorFallbackFail:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown vector fallback %q", s)
+	}
+}
+
+// Budgets splits a request's deadline between its legs.
+type Budgets struct {
+	// EncodingReserve ends the legs this long before the request deadline,
+	// leaving the time to encode and write the response.
+	EncodingReserve time.Duration
+	User            scatter.Budget
+	Permissions     scatter.Budget
+	Vector          scatter.Budget
+}
+
+type Option func(h *ChatSummaryHandler)
+
+func WithVectorFallback(fallback VectorFallback) Option {
+	return func(h *ChatSummaryHandler) {
+		h.vectorFallback = fallback
+	}
+}
+
+func WithBudgets(budgets Budgets) Option {
+	return func(h *ChatSummaryHandler) {
+		h.budgets = budgets
+	}
+}
+
+func WithMetrics(m *metrics.Metrics) Option {
+	return func(h *ChatSummaryHandler) {
+		h.metrics = m
+	}
+}
+
+// WithTracerProvider overrides the global tracer provider used for request
+// spans. Leg and backend spans follow the provider of the request span.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(h *ChatSummaryHandler) {
+		h.tracer = tp.Tracer(tracing.InstrumentationName)
+	}
+}
+
+func WithLogger(logger *slog.Logger) Option {
+	return func(h *ChatSummaryHandler) {
+		h.logger = logger
+	}
+}
@@ -0,0 +1,201 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+type Status string
+
+const (
+	StatusUp   Status = "up"
+	StatusDown Status = "down"
+
+	StatusHealthy  Status = "healthy"
+	StatusAlive    Status = "alive"
+	StatusReady    Status = "ready"
+	StatusNotReady Status = "not_ready"
+)
+
+// Conn is the part of *grpc.ClientConn the checker needs.
+type Conn interface {
+	GetState() connectivity.State
+	Connect()
+}
+
+// Dependency is a backend the gateway needs to serve traffic.
+type Dependency struct {
+	Name string
+	// Critical dependencies make the gateway unready while they are down.
+	Critical bool
+	Conn     Conn
+	// Health, if set, is asked with the standard gRPC health checking
+	// protocol instead of relying on the connection state alone.
+	Health healthpb.HealthClient
+	// Service is the name sent in health check requests; empty means the
+	// server as a whole.
+	Service string
+}
+
+type DependencyStatus struct {
+	Name     string `json:"name"`
+	Status   Status `json:"status"`
+	Critical bool   `json:"critical"`
+	State    string `json:"state"`
+	Error    string `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status       Status             `json:"status"`
+	Dependencies []DependencyStatus `json:"dependencies"`
+	Timestamp    time.Time          `json:"timestamp"`
+}
+
+type Checker struct {
+	dependencies []Dependency
+	timeout      time.Duration
+}
+
+// NewChecker returns a checker that gives each health check RPC at most
+// timeout.
+func NewChecker(timeout time.Duration, dependencies ...Dependency) *Checker {
+	return &Checker{
+		dependencies: dependencies,
+		timeout:      timeout,
+	}
+}
+
+// Check probes every dependency concurrently. The gateway is ready when no
+// critical dependency is down.
+func (c *Checker) Check(ctx context.Context) *Report {
+	report := &Report{
+		Status:       StatusReady,
+		Dependencies: make([]DependencyStatus, len(c.dependencies)),
+		Timestamp:    time.Now(),
+	}
+
+	var wg sync.WaitGroup
+	for i, dep := range c.dependencies {
+		wg.Add(1)
+		go func(i int, dep Dependency) {
+			defer wg.Done()
+			report.Dependencies[i] = c.check(ctx, dep)
+		}(i, dep)
+	}
+	wg.Wait()
+
+	for _, dep := range report.Dependencies {
+		if dep.Critical && dep.Status == StatusDown {
+			report.Status = StatusNotReady
+		}
+	}
+
+	return report
+}
+
+func (c *Checker) check(ctx context.Context, dep Dependency) DependencyStatus {
+	state := dep.Conn.GetState()
+	result := DependencyStatus{
+		Name:     dep.Name,
+		Status:   StatusDown,
+		Critical: dep.Critical,
+		State:    state.String(),
+	}
+
+	// A fresh or idle connection only dials once something uses it.
+	if state == connectivity.Idle {
+		dep.Conn.Connect()
+	}
+
+	if dep.Health == nil {
+		if state == connectivity.Ready {
+			result.Status = StatusUp
+		}
+		return result
+	}
+
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	resp, err := dep.Health.Check(ctx, &healthpb.HealthCheckRequest{Service: dep.Service})
+	state = dep.Conn.GetState()
+	switch {
+	case status.Code(err) == codes.Unimplemented:
+		// The backend does not serve grpc.health.v1; it answered, so fall
+		// back to the connection state.
+		if state == connectivity.Ready {
+			result.Status = StatusUp
+		}
+	case err != nil:
+		result.Error = err.Error()
+	case resp.GetStatus() != healthpb.HealthCheckResponse_SERVING:
+		result.Error = resp.GetStatus().String()
+	default:
+		result.Status = StatusUp
+	}
+	result.State = state.String()
+
+	return result
+}
+
+// LivenessHandler reports that the process is up and able to serve HTTP. It
+// deliberately ignores backends, so that an outage does not get the gateway
+// restarted.
+func LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, &Report{
+			Status:       StatusAlive,
+			Dependencies: []DependencyStatus{},
+			Timestamp:    time.Now(),
+		})
+	})
+}
+
+// LegacyHandler serves the original /health contract, a 200 with
+// {"status":"healthy","timestamp":...}, for clients written against it.
+// Like LivenessHandler it ignores backends.
+func LegacyHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(struct {
+			Status    Status `json:"status"`
+			Timestamp string `json:"timestamp"`
+		}{
+			Status:    StatusHealthy,
+			Timestamp: time.Now().Format(time.RFC3339),
+		})
+	})
+}
+
+// ReadinessHandler responds 200 when checker reports ready and 503
+// otherwise, with the per-dependency report as the body.
+func ReadinessHandler(checker *Checker) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := checker.Check(r.Context())
+
+		statusCode := http.StatusOK
+		if report.Status != StatusReady {
+			statusCode = http.StatusServiceUnavailable
+		}
+		writeJSON(w, statusCode, report)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, report *Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,181 @@
+package hedge
+
+import (
+	"context"
+	"math"
+	"slices"
+	"sync"
+	"time"
+)
+
+type Settings struct {
+	// Percentile of observed latencies after which a hedge is fired,
+	// e.g. 0.95.
+	Percentile float64
+	// InitialDelay is used until MinSamples latencies have been observed.
+	InitialDelay time.Duration
+	MinSamples   int
+	// SampleSize is the number of most recent latencies kept.
+	SampleSize int
+	// MaxHedgesPerSecond caps the extra load hedging can put on a backend.
+	MaxHedgesPerSecond float64
+}
+
+type Hedger struct {
+	name     string
+	settings Settings
+
+	mu sync.Mutex
+	// samples is a ring of the most recent latencies and sorted holds the
+	// same latencies in order, so that Delay need not sort.
+	samples  []time.Duration
+	sorted   []time.Duration
+	next     int
+	count    int
+	tokens   float64
+	lastFill time.Time
+}
+
+func New(name string, settings Settings) *Hedger {
+	if settings.SampleSize < 1 {
+		settings.SampleSize = 100
+	}
+
+	return &Hedger{
+		name:     name,
+		settings: settings,
+		samples:  make([]time.Duration, settings.SampleSize),
+		sorted:   make([]time.Duration, 0, settings.SampleSize),
+		tokens:   burst(settings.MaxHedgesPerSecond),
+		lastFill: time.Now(),
+	}
+}
+
+func (h *Hedger) Name() string {
+	return h.name
+}
+
+// Delay returns how long to wait for the primary call before hedging.
+func (h *Hedger) Delay() time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.count < h.settings.MinSamples || h.count == 0 {
+		return h.settings.InitialDelay
+	}
+
+	n := len(h.sorted)
+	idx := int(math.Ceil(h.settings.Percentile*float64(n))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= n {
+		idx = n - 1
+	}
+
+	return h.sorted[idx]
+}
+
+func (h *Hedger) observe(latency time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.count >= len(h.samples) {
+		i, _ := slices.BinarySearch(h.sorted, h.samples[h.next])
+		h.sorted = slices.Delete(h.sorted, i, i+1)
+	}
+	i, _ := slices.BinarySearch(h.sorted, latency)
+	h.sorted = slices.Insert(h.sorted, i, latency)
+
+	h.samples[h.next] = latency
+	h.next = (h.next + 1) % len(h.samples)
+	h.count++
+}
+
+// allow takes a token from the hedge budget.
+func (h *Hedger) allow() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	h.tokens = math.Min(
+		burst(h.settings.MaxHedgesPerSecond),
+		h.tokens+now.Sub(h.lastFill).Seconds()*h.settings.MaxHedgesPerSecond,
+	)
+	h.lastFill = now
+
+	if h.tokens < 1 {
+		return false
+	}
+	h.tokens--
+	return true
+}
+
+func burst(perSecond float64) float64 {
+	return math.Max(1, perSecond)
+}
+
+type attempt[T any] struct {
+	value T
+	err   error
+}
+
+// Do runs call and, if it has not returned after h.Delay(), fires a second
+// identical call. The first successful result wins and the other call is
+// cancelled; if both fail, the last error is returned. No hedge is fired,
+// and no token spent, when less than the delay remains before ctx's
+// deadline, since the hedge would most likely not finish in time.
+//
+// The latency observed is always the primary's: when a hedge wins, the
+// time the primary had been running is recorded as a lower bound of it.
+// Recording the hedge's own latency instead would pull the percentile
+// down to the fast path and fire hedges ever earlier.
+func Do[T any](ctx context.Context, h *Hedger, call func(ctx context.Context) (T, error)) (T, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan attempt[T], 2)
+	launch := func() {
+		go func() {
+			value, err := call(ctx)
+			results <- attempt[T]{value: value, err: err}
+		}()
+	}
+
+	start := time.Now()
+	launch()
+	inFlight := 1
+
+	delay := h.Delay()
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	var last attempt[T]
+	for {
+		select {
+		case res := <-results:
+			inFlight--
+			if res.err == nil {
+				h.observe(time.Since(start))
+				return res.value, nil
+			}
+			last = res
+			if inFlight == 0 {
+				return last.value, last.err
+			}
+
+		case <-timer.C:
+			if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+				continue
+			}
+			if h.allow() {
+				launch()
+				inFlight++
+			}
+
+		case <-ctx.Done():
+			var zero T
+			return zero, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package loadshed
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/vwency/resilient-scatter-gather/internal/metrics"
+	"github.com/vwency/resilient-scatter-gather/internal/models"
+	"github.com/vwency/resilient-scatter-gather/internal/requestid"
+)
+
+type Settings struct {
+	// Target is the latency requests should complete within, usually the
+	// SLA. Each slower request shrinks the limit, as does each one that
+	// missed its deadline.
+	Target time.Duration
+	// InitialLimit, MinLimit and MaxLimit bound the number of requests
+	// handled at the same time.
+	InitialLimit int
+	MinLimit     int
+	MaxLimit     int
+	// Backoff multiplies the limit when a request misses Target.
+	Backoff float64
+}
+
+// Limiter caps the requests in flight with an AIMD limit: the limit grows
+// by one for every limit's worth of requests that complete within Target
+// while the limit is in use, and is multiplied by Backoff when a request
+// misses it or is dropped. Requests the gateway cuts short at its deadline
+// finish in time, so the handlers mark them dropped with MarkDropped; that
+// is how backend slowness shows. Failures that come back fast say nothing
+// about load and leave the limit alone. Requests over the limit are
+// rejected instead of queued, so that the ones admitted still meet the SLA.
+type Limiter struct {
+	name     string
+	settings Settings
+	now      func() time.Time
+	metrics  *metrics.Metrics
+
+	mu           sync.Mutex
+	limit        float64
+	inFlight     int
+	lastDecrease time.Time
+}
+
+type Option func(l *Limiter)
+
+func WithMetrics(m *metrics.Metrics) Option {
+	return func(l *Limiter) {
+		l.metrics = m
+	}
+}
+
+// New returns a limiter whose metrics are labeled with name.
+func New(name string, settings Settings, opts ...Option) *Limiter {
+	return NewWithClock(name, settings, time.Now, opts...)
+}
+
+// NewWithClock times each admitted request with now instead of the wall
+// clock; that duration is what gets compared with Target.
+func NewWithClock(name string, settings Settings, now func() time.Time, opts ...Option) *Limiter {
+	if settings.MinLimit < 1 {
+		settings.MinLimit = 1
+	}
+	if settings.MaxLimit < settings.MinLimit {
+		settings.MaxLimit = max(settings.MinLimit, 1000)
+	}
+	if settings.InitialLimit < 1 {
+		settings.InitialLimit = 20
+	}
+	settings.InitialLimit = min(max(settings.InitialLimit, settings.MinLimit), settings.MaxLimit)
+	if settings.Backoff <= 0 || settings.Backoff >= 1 {
+		settings.Backoff = 0.9
+	}
+
+	l := &Limiter{
+		name:     name,
+		settings: settings,
+		now:      now,
+		limit:    float64(settings.InitialLimit),
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	l.metrics.ConcurrencyLimit(name, settings.InitialLimit)
+	return l
+}
+
+// Limit returns the current number of requests admitted at the same time.
+func (l *Limiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int(l.limit)
+}
+
+// Acquire admits a request if the limit allows it. done must be called
+// exactly once when an admitted request completes, with whether it was
+// dropped: cut short because it ran out of time.
+func (l *Limiter) Acquire() (done func(dropped bool), ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.inFlight >= int(l.limit) {
+		l.metrics.Shed(l.name)
+		return nil, false
+	}
+	l.inFlight++
+
+	start := l.now()
+	return func(dropped bool) { l.release(start, dropped) }, true
+}
+
+func (l *Limiter) release(start time.Time, dropped bool) {
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	inFlight := l.inFlight
+	l.inFlight--
+
+	switch {
+	case dropped || now.Sub(start) > l.settings.Target:
+		// Requests that started before the last decrease were slowed by
+		// the load that caused it and must not shrink the limit again.
+		if start.Before(l.lastDecrease) {
+			return
+		}
+		l.limit = max(l.limit*l.settings.Backoff, float64(l.settings.MinLimit))
+		l.lastDecrease = now
+	case float64(inFlight)*2 >= l.limit:
+		// Only grow a limit that is in use; a mostly idle gateway says
+		// nothing about how much more it could take.
+		l.limit = min(l.limit+1/l.limit, float64(l.settings.MaxLimit))
+	default:
+		return
+	}
+	l.metrics.ConcurrencyLimit(l.name, int(l.limit))
+}
+
+type admissionKey struct{}
+
+type admission struct {
+	dropped atomic.Bool
+}
+
+// MarkDropped records that the request running with ctx missed its
+// deadline, so that Middleware counts it as dropped. It is a no-op outside
+// of Middleware.
+func MarkDropped(ctx context.Context) {
+	if a, ok := ctx.Value(admissionKey{}).(*admission); ok {
+		a.dropped.Store(true)
+	}
+}
+
+// Middleware answers requests over the limit with 503 right away.
+func (l *Limiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		done, ok := l.Acquire()
+		if !ok {
+			overloaded(w, r)
+			return
+		}
+
+		a := &admission{}
+		defer func() {
+			done(a.dropped.Load())
+		}()
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), admissionKey{}, a)))
+	})
+}
+
+func overloaded(w http.ResponseWriter, r *http.Request) {
+	requestID, _ := requestid.FromContext(r.Context())
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", "1")
+	w.WriteHeader(http.StatusServiceUnavailable)
+
+	_ = json.NewEncoder(w).Encode(&models.ErrorResponse{
+		Error:     http.StatusText(http.StatusServiceUnavailable),
+		Code:      http.StatusServiceUnavailable,
+		Message:   "server overloaded, try again later",
+		RequestID: requestID,
+	})
+}
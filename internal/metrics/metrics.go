@@ -0,0 +1,176 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/status"
+)
+
+const namespace = "gateway"
+
+// Metrics holds the gateway's Prometheus collectors. All methods are safe to
+// call on a nil *Metrics, which makes instrumentation optional.
+type Metrics struct {
+	requestsTotal      *prometheus.CounterVec
+	requestDuration    *prometheus.HistogramVec
+	requestsInFlight   prometheus.Gauge
+	degradedResponses  *prometheus.CounterVec
+	slaBreaches        *prometheus.CounterVec
+	backendDuration    *prometheus.HistogramVec
+	backendErrors      *prometheus.CounterVec
+	backendCallsActive *prometheus.GaugeVec
+	rateLimited        *prometheus.CounterVec
+	concurrencyLimit   *prometheus.GaugeVec
+	shedRequests       *prometheus.CounterVec
+}
+
+func New(reg prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		requestsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests handled, by endpoint and response status code.",
+		}, []string{"endpoint", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency, by endpoint and response status code.",
+			Buckets:   []float64{.005, .01, .025, .05, .1, .15, .2, .25, .5, 1},
+		}, []string{"endpoint", "status"}),
+		requestsInFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "http_requests_in_flight",
+			Help:      "HTTP requests currently being handled.",
+		}),
+		degradedResponses: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "degraded_responses_total",
+			Help:      "Successful responses served without an optional leg, by leg.",
+		}, []string{"leg"}),
+		slaBreaches: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "sla_breaches_total",
+			Help:      "Requests that took longer than their endpoint's response time SLA, by endpoint.",
+		}, []string{"endpoint"}),
+		backendDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "backend_call_duration_seconds",
+			Help:      "Backend gRPC call latency, by service.",
+			Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .2, .5},
+		}, []string{"service"}),
+		backendErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "backend_call_errors_total",
+			Help:      "Failed backend gRPC calls, by service and gRPC code.",
+		}, []string{"service", "code"}),
+		backendCallsActive: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "backend_calls_in_flight",
+			Help:      "Backend gRPC calls currently in flight, by service.",
+		}, []string{"service"}),
+		rateLimited: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "rate_limited_requests_total",
+			Help:      "Requests rejected with 429, by what the limit was keyed on.",
+		}, []string{"key"}),
+		concurrencyLimit: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "concurrency_limit",
+			Help:      "Requests admitted at the same time by the adaptive limiter, by endpoint.",
+		}, []string{"endpoint"}),
+		shedRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "shed_requests_total",
+			Help:      "Requests rejected with 503 over the concurrency limit, by endpoint.",
+		}, []string{"endpoint"}),
+	}
+
+	reg.MustRegister(
+		m.requestsTotal,
+		m.requestDuration,
+		m.requestsInFlight,
+		m.degradedResponses,
+		m.slaBreaches,
+		m.backendDuration,
+		m.backendErrors,
+		m.backendCallsActive,
+		m.rateLimited,
+		m.concurrencyLimit,
+		m.shedRequests,
+	)
+
+	return m
+}
+
+func (m *Metrics) RequestStarted() {
+	if m == nil {
+		return
+	}
+	m.requestsInFlight.Inc()
+}
+
+// RequestFinished records a request to endpoint that was answered with
+// statusCode after elapsed, and whether it missed that endpoint's sla.
+func (m *Metrics) RequestFinished(endpoint string, statusCode int, elapsed, sla time.Duration) {
+	if m == nil {
+		return
+	}
+
+	code := strconv.Itoa(statusCode)
+	m.requestsInFlight.Dec()
+	m.requestsTotal.WithLabelValues(endpoint, code).Inc()
+	m.requestDuration.WithLabelValues(endpoint, code).Observe(elapsed.Seconds())
+
+	if sla > 0 && elapsed > sla {
+		m.slaBreaches.WithLabelValues(endpoint).Inc()
+	}
+}
+
+func (m *Metrics) Degraded(leg string) {
+	if m == nil {
+		return
+	}
+	m.degradedResponses.WithLabelValues(leg).Inc()
+}
+
+func (m *Metrics) BackendCallStarted(service string) {
+	if m == nil {
+		return
+	}
+	m.backendCallsActive.WithLabelValues(service).Inc()
+}
+
+func (m *Metrics) BackendCallFinished(service string, elapsed time.Duration, err error) {
+	if m == nil {
+		return
+	}
+
+	m.backendCallsActive.WithLabelValues(service).Dec()
+	m.backendDuration.WithLabelValues(service).Observe(elapsed.Seconds())
+	if err != nil {
+		m.backendErrors.WithLabelValues(service, status.Code(err).String()).Inc()
+	}
+}
+
+func (m *Metrics) RateLimited(key string) {
+	if m == nil {
+		return
+	}
+	m.rateLimited.WithLabelValues(key).Inc()
+}
+
+func (m *Metrics) ConcurrencyLimit(endpoint string, limit int) {
+	if m == nil {
+		return
+	}
+	m.concurrencyLimit.WithLabelValues(endpoint).Set(float64(limit))
+}
+
+func (m *Metrics) Shed(endpoint string) {
+	if m == nil {
+		return
+	}
+	m.shedRequests.WithLabelValues(endpoint).Inc()
+}
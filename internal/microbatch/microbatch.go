@@ -0,0 +1,182 @@
+package microbatch
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type Settings struct {
+	// Window is how long the first call of a batch waits for others to
+	// join before the batch is sent.
+	Window time.Duration
+	// MaxSize sends a batch as soon as it holds that many distinct keys;
+	// zero means no limit.
+	MaxSize int
+}
+
+// BatchFunc fetches keys in one call. It must return one value per key, in
+// the order of keys, and errs either nil or with one entry per key, nil
+// for the keys that succeeded. err fails every key and is meant for the
+// call itself failing, so that one bad key does not fail the callers of
+// the others.
+type BatchFunc[K comparable, V any] func(ctx context.Context, keys []K) (values []V, errs []error, err error)
+
+type batch[K comparable, V any] struct {
+	keys  []K
+	index map[K]int
+
+	// ctx is the first caller's context; the batch keeps its values.
+	ctx       context.Context
+	deadline  time.Time
+	unbounded bool
+
+	waiters int
+	timer   *time.Timer
+	cancel  context.CancelFunc
+
+	done   chan struct{}
+	values []V
+	errs   []error
+	err    error
+}
+
+// Batcher merges concurrent single-key calls into one BatchFunc call.
+//
+// Like coalesce.Group, the batch does not run on any single caller's
+// context: it keeps the first caller's values, runs until the latest
+// deadline of its callers and is only cancelled once every caller has
+// given up. Concurrent calls for the same key share one slot.
+type Batcher[K comparable, V any] struct {
+	settings Settings
+	fn       BatchFunc[K, V]
+
+	mu      sync.Mutex
+	pending *batch[K, V]
+}
+
+func New[K comparable, V any](settings Settings, fn BatchFunc[K, V]) *Batcher[K, V] {
+	return &Batcher[K, V]{
+		settings: settings,
+		fn:       fn,
+	}
+}
+
+// Do adds key to the pending batch, opening one if needed, and waits for
+// its value.
+func (b *Batcher[K, V]) Do(ctx context.Context, key K) (V, error) {
+	b.mu.Lock()
+	p := b.pending
+	if p == nil {
+		p = b.open(ctx)
+	}
+	i := p.add(ctx, key)
+	if b.settings.MaxSize > 0 && len(p.keys) >= b.settings.MaxSize {
+		b.flush(p)
+	}
+	b.mu.Unlock()
+
+	select {
+	case <-p.done:
+		if p.err != nil {
+			var zero V
+			return zero, p.err
+		}
+		if p.errs != nil && p.errs[i] != nil {
+			var zero V
+			return zero, p.errs[i]
+		}
+		return p.values[i], nil
+	case <-ctx.Done():
+		b.leave(p)
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// open must be called with b.mu held.
+func (b *Batcher[K, V]) open(ctx context.Context) *batch[K, V] {
+	p := &batch[K, V]{
+		index: make(map[K]int),
+		ctx:   ctx,
+		done:  make(chan struct{}),
+	}
+	p.timer = time.AfterFunc(b.settings.Window, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if b.pending == p {
+			b.flush(p)
+		}
+	})
+	b.pending = p
+	return p
+}
+
+func (p *batch[K, V]) add(ctx context.Context, key K) int {
+	p.waiters++
+
+	if deadline, ok := ctx.Deadline(); !ok {
+		p.unbounded = true
+	} else if deadline.After(p.deadline) {
+		p.deadline = deadline
+	}
+
+	if i, ok := p.index[key]; ok {
+		return i
+	}
+	p.index[key] = len(p.keys)
+	p.keys = append(p.keys, key)
+	return len(p.keys) - 1
+}
+
+// flush sends p. It must be called with b.mu held.
+func (b *Batcher[K, V]) flush(p *batch[K, V]) {
+	p.timer.Stop()
+	b.pending = nil
+
+	base := context.WithoutCancel(p.ctx)
+	var ctx context.Context
+	if p.unbounded {
+		ctx, p.cancel = context.WithCancel(base)
+	} else {
+		ctx, p.cancel = context.WithDeadline(base, p.deadline)
+	}
+
+	go func() {
+		defer p.cancel()
+
+		values, errs, err := b.fn(ctx, p.keys)
+		switch {
+		case err != nil:
+		case len(values) != len(p.keys):
+			err = fmt.Errorf("batch of %d keys returned %d values", len(p.keys), len(values))
+		case errs != nil && len(errs) != len(p.keys):
+			err = fmt.Errorf("batch of %d keys returned %d errors", len(p.keys), len(errs))
+		}
+		p.values, p.errs, p.err = values, errs, err
+		close(p.done)
+	}()
+}
+
+// leave drops a caller that gave up. A batch nobody waits for anymore is
+// cancelled if it was sent and discarded otherwise; keys of callers that
+// left stay in a batch others still wait for.
+func (b *Batcher[K, V]) leave(p *batch[K, V]) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	p.waiters--
+	if p.waiters > 0 {
+		return
+	}
+
+	if b.pending == p {
+		p.timer.Stop()
+		b.pending = nil
+		p.err = context.Canceled
+		close(p.done)
+		return
+	}
+	p.cancel()
+}
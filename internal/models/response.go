@@ -13,11 +13,44 @@ type ChatSummaryResponse struct {
 	Permissions *pb_permissions.CheckAccessResponse `json:"permissions"`
 	Context     *pb_vector.GetContextResponse       `json:"context,omitempty"`
 	Degraded    bool                                `json:"degraded"`
+	Legs        map[string]LegStatus                `json:"legs"`
 	Timestamp   time.Time                           `json:"timestamp"`
 }
 
+type LegStatus struct {
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	ErrorCode string  `json:"error_code,omitempty"`
+}
+
 type ErrorResponse struct {
-	Error   string `json:"error"`
-	Code    int    `json:"code"`
-	Message string `json:"message"`
+	Error     string `json:"error"`
+	Code      int    `json:"code"`
+	Message   string `json:"message"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+type BatchChatSummaryRequest struct {
+	UserID  string   `json:"user_id"`
+	ChatIDs []string `json:"chat_ids"`
+}
+
+type BatchChatSummaryResponse struct {
+	User      *pb_user.GetUserResponse `json:"user"`
+	Items     []BatchChatSummaryItem   `json:"items"`
+	Degraded  bool                     `json:"degraded"`
+	Timestamp time.Time                `json:"timestamp"`
+}
+
+// BatchChatSummaryItem is the outcome for one chat of a batch. Code is the
+// HTTP status the chat would have had as a single summary request.
+type BatchChatSummaryItem struct {
+	ChatID      string                              `json:"chat_id"`
+	Status      string                              `json:"status"`
+	Code        int                                 `json:"code"`
+	Permissions *pb_permissions.CheckAccessResponse `json:"permissions,omitempty"`
+	Context     *pb_vector.GetContextResponse       `json:"context,omitempty"`
+	Degraded    bool                                `json:"degraded"`
+	Legs        map[string]LegStatus                `json:"legs,omitempty"`
+	Error       string                              `json:"error,omitempty"`
 }
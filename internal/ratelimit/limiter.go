@@ -0,0 +1,158 @@
+package ratelimit
+
+import (
+	"encoding/json"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/vwency/resilient-scatter-gather/internal/auth"
+	"github.com/vwency/resilient-scatter-gather/internal/metrics"
+	"github.com/vwency/resilient-scatter-gather/internal/models"
+	"github.com/vwency/resilient-scatter-gather/internal/requestid"
+)
+
+const (
+	HeaderLimit     = "X-RateLimit-Limit"
+	HeaderRemaining = "X-RateLimit-Remaining"
+	HeaderReset     = "X-RateLimit-Reset"
+)
+
+// Settings are the limits of each kind of caller. Only authenticated
+// callers get a limit of their own; unauthenticated ones, whatever headers
+// they send, are limited by IP alone, since anything else they present is
+// unverified and would let a caller mint fresh buckets at will.
+type Settings struct {
+	// User limits authenticated callers, keyed by their subject.
+	User Rate
+	// IP limits every request, keyed by client address, which bounds
+	// each address whatever credentials it sends.
+	IP Rate
+	// TrustForwardedFor takes the client address from the last
+	// X-Forwarded-For entry, which is only safe behind a proxy that
+	// appends it.
+	TrustForwardedFor bool
+}
+
+const (
+	kindUser = "user"
+	kindIP   = "ip"
+)
+
+// Limiter takes one token per request from the bucket of its caller.
+type Limiter struct {
+	store    Store
+	settings Settings
+	metrics  *metrics.Metrics
+	logger   *slog.Logger
+}
+
+type Option func(l *Limiter)
+
+func WithMetrics(m *metrics.Metrics) Option {
+	return func(l *Limiter) {
+		l.metrics = m
+	}
+}
+
+func WithLogger(logger *slog.Logger) Option {
+	return func(l *Limiter) {
+		l.logger = logger
+	}
+}
+
+func New(store Store, settings Settings, opts ...Option) *Limiter {
+	l := &Limiter{
+		store:    store,
+		settings: settings,
+		logger:   slog.Default(),
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+func (l *Limiter) clientIP(r *http.Request) string {
+	if l.settings.TrustForwardedFor {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			entries := strings.Split(forwarded, ",")
+			return strings.TrimSpace(entries[len(entries)-1])
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// IPMiddleware limits every request by client address. It belongs in
+// front of authentication, so that requests with bad credentials are
+// limited too.
+func (l *Limiter) IPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if l.allow(w, r, kindIP, "ip:"+l.clientIP(r), l.settings.IP) {
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// Middleware limits requests by authenticated user on top of
+// IPMiddleware. Unauthenticated requests are passed on.
+func (l *Limiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity, ok := auth.FromContext(r.Context())
+		if !ok || l.allow(w, r, kindUser, "user:"+identity.Subject, l.settings.User) {
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// allow takes a token for key and reports whether r may proceed. Rejected
+// requests are answered with 429 and Retry-After; allowed ones get the
+// rate-limit headers too. If the store fails the request is let through,
+// so that a broken store does not take the gateway down with it.
+func (l *Limiter) allow(w http.ResponseWriter, r *http.Request, kind, key string, rate Rate) bool {
+	if rate.PerSecond <= 0 {
+		return true
+	}
+
+	decision, err := l.store.Take(r.Context(), key, rate)
+	if err != nil {
+		l.logger.WarnContext(r.Context(), "rate limit store failed, allowing request", "error", err)
+		return true
+	}
+
+	w.Header().Set(HeaderLimit, strconv.Itoa(decision.Limit))
+	w.Header().Set(HeaderRemaining, strconv.Itoa(decision.Remaining))
+	w.Header().Set(HeaderReset, strconv.Itoa(ceilSeconds(decision.Reset.Seconds())))
+
+	if !decision.Allowed {
+		w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(decision.RetryAfter.Seconds())))
+		l.metrics.RateLimited(kind)
+		tooManyRequests(w, r)
+		return false
+	}
+	return true
+}
+
+func ceilSeconds(s float64) int {
+	return int(math.Ceil(s))
+}
+
+func tooManyRequests(w http.ResponseWriter, r *http.Request) {
+	requestID, _ := requestid.FromContext(r.Context())
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusTooManyRequests)
+
+	_ = json.NewEncoder(w).Encode(&models.ErrorResponse{
+		Error:     http.StatusText(http.StatusTooManyRequests),
+		Code:      http.StatusTooManyRequests,
+		Message:   "rate limit exceeded",
+		RequestID: requestID,
+	})
+}
@@ -0,0 +1,126 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Rate is a token bucket refilled at PerSecond tokens a second that holds
+// at most Burst tokens. A zero PerSecond means no limit.
+type Rate struct {
+	PerSecond float64
+	Burst     int
+}
+
+// Decision is the outcome of taking a token.
+type Decision struct {
+	Allowed bool
+	// Limit is the bucket size and Remaining the whole tokens left.
+	Limit     int
+	Remaining int
+	// RetryAfter is how long until the next token, when not allowed.
+	RetryAfter time.Duration
+	// Reset is how long until the bucket is full again.
+	Reset time.Duration
+}
+
+// Store keeps the buckets. Implementations backed by a shared store let
+// several gateway instances enforce one limit.
+type Store interface {
+	Take(ctx context.Context, key string, rate Rate) (Decision, error)
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	// full is when the bucket will have refilled completely.
+	full time.Time
+}
+
+// MemoryStore keeps buckets in process. Buckets that have refilled
+// completely are dropped now and then, since they hold no state a new
+// bucket would not.
+type MemoryStore struct {
+	now           func() time.Time
+	sweepInterval time.Duration
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return NewMemoryStoreWithClock(time.Now)
+}
+
+// NewMemoryStoreWithClock refills buckets by the time now reports, so a
+// caller controls how many tokens accrue between two takes.
+func NewMemoryStoreWithClock(now func() time.Time) *MemoryStore {
+	return &MemoryStore{
+		now:           now,
+		sweepInterval: time.Minute,
+		buckets:       make(map[string]*bucket),
+		lastSweep:     now(),
+	}
+}
+
+func (s *MemoryStore) Take(ctx context.Context, key string, rate Rate) (Decision, error) {
+	if rate.PerSecond <= 0 {
+		return Decision{Allowed: true}, nil
+	}
+	now := s.now()
+	burst := float64(max(rate.Burst, 1))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: burst, last: now}
+		s.buckets[key] = b
+	}
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*rate.PerSecond)
+	b.last = now
+
+	d := Decision{Limit: int(burst)}
+	if b.tokens >= 1 {
+		b.tokens--
+		d.Allowed = true
+	} else {
+		d.RetryAfter = seconds((1 - b.tokens) / rate.PerSecond)
+	}
+	d.Remaining = int(b.tokens)
+	d.Reset = seconds((burst - b.tokens) / rate.PerSecond)
+	b.full = now.Add(d.Reset)
+
+	return d, nil
+}
+
+// sweep drops full buckets at most once per sweepInterval.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < s.sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		if !now.Before(b.full) {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+// Len returns the number of buckets held.
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.buckets)
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
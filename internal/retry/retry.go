@@ -0,0 +1,165 @@
+package retry
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/vwency/resilient-scatter-gather/internal/grpccode"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type Policy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	// Jitter randomly shortens each backoff by up to this fraction.
+	Jitter float64
+	// MinAttemptTime is the least amount of time an attempt needs to be
+	// useful; no retry is started if less than that would remain before
+	// the deadline once the backoff has elapsed.
+	MinAttemptTime time.Duration
+	RetryableCodes []codes.Code
+}
+
+// ParseCodes converts gRPC code names such as "UNAVAILABLE" into codes,
+// spelled any way grpccode.Parse accepts.
+func ParseCodes(names []string) ([]codes.Code, error) {
+	result := make([]codes.Code, 0, len(names))
+	for _, name := range names {
+		c, err := grpccode.Parse(name)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, c)
+	}
+	return result, nil
+}
+
+// Budget limits retries across all callers sharing it. Every call deposits
+// Ratio tokens and every retry withdraws one, so retries cannot exceed
+// roughly Ratio of the overall traffic once the initial tokens are spent.
+type Budget struct {
+	ratio     float64
+	maxTokens float64
+
+	mu     sync.Mutex
+	tokens float64
+}
+
+func NewBudget(ratio float64, maxTokens int) *Budget {
+	return &Budget{
+		ratio:     ratio,
+		maxTokens: float64(maxTokens),
+		tokens:    float64(maxTokens),
+	}
+}
+
+func (b *Budget) deposit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens = math.Min(b.maxTokens, b.tokens+b.ratio)
+}
+
+func (b *Budget) withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+type Retrier struct {
+	name   string
+	policy Policy
+	budget *Budget
+}
+
+func New(name string, policy Policy, budget *Budget) *Retrier {
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
+	}
+	if policy.Multiplier < 1 {
+		policy.Multiplier = 1
+	}
+
+	return &Retrier{
+		name:   name,
+		policy: policy,
+		budget: budget,
+	}
+}
+
+func (r *Retrier) Name() string {
+	return r.name
+}
+
+// Do calls call until it succeeds, returns a non-retryable error, runs out
+// of attempts or retry budget, or the context deadline no longer leaves room
+// for another attempt.
+func Do[T any](ctx context.Context, r *Retrier, call func(ctx context.Context) (T, error)) (T, error) {
+	if r.budget != nil {
+		r.budget.deposit()
+	}
+
+	for attempt := 1; ; attempt++ {
+		value, err := call(ctx)
+		if err == nil || attempt >= r.policy.MaxAttempts || !r.retryable(err) {
+			return value, err
+		}
+
+		backoff := r.backoff(attempt)
+		if !r.fits(ctx, backoff) {
+			return value, err
+		}
+		if r.budget != nil && !r.budget.withdraw() {
+			return value, err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return value, err
+		case <-timer.C:
+		}
+	}
+}
+
+func (r *Retrier) retryable(err error) bool {
+	code := status.Code(err)
+	for _, c := range r.policy.RetryableCodes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Retrier) backoff(attempt int) time.Duration {
+	backoff := float64(r.policy.InitialBackoff) * math.Pow(r.policy.Multiplier, float64(attempt-1))
+	if r.policy.MaxBackoff > 0 {
+		backoff = math.Min(backoff, float64(r.policy.MaxBackoff))
+	}
+	backoff *= 1 - r.policy.Jitter*rand.Float64()
+
+	return time.Duration(backoff)
+}
+
+// fits reports whether an attempt started after backoff would still have
+// MinAttemptTime left before the context deadline.
+func (r *Retrier) fits(ctx context.Context, backoff time.Duration) bool {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return ctx.Err() == nil
+	}
+	return time.Until(deadline)-backoff >= r.policy.MinAttemptTime
+}
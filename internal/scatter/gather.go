@@ -0,0 +1,366 @@
+package scatter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/vwency/resilient-scatter-gather/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type Criticality int
+
+const (
+	// Required legs must succeed, otherwise the whole gather fails.
+	Required Criticality = iota
+	// Optional legs may fail or time out; the gather is then marked degraded.
+	Optional
+)
+
+func (c Criticality) String() string {
+	if c == Optional {
+		return "optional"
+	}
+	return "required"
+}
+
+type Status string
+
+const (
+	StatusOK      Status = "ok"
+	StatusTimeout Status = "timeout"
+	StatusError   Status = "error"
+	// StatusSkipped marks legs that were cancelled before finishing because
+	// the gather had already failed, or not started because less than
+	// their Budget.MinUseful was left.
+	StatusSkipped Status = "skipped"
+	// StatusCached marks legs whose value was served from a cache, see
+	// MarkCached.
+	StatusCached Status = "cached"
+)
+
+type legStateKey struct{}
+
+type legState struct {
+	cached atomic.Bool
+}
+
+// MarkCached records that the value returned by the leg running with ctx
+// was served from a cache rather than the backend. It is a no-op outside
+// of a leg.
+func MarkCached(ctx context.Context) {
+	if state, ok := ctx.Value(legStateKey{}).(*legState); ok {
+		state.cached.Store(true)
+	}
+}
+
+// ErrBudgetExhausted is the error of legs that were not started because
+// too little time was left before the gather deadline.
+var ErrBudgetExhausted = errors.New("not enough time left to start leg")
+
+// Budget derives a leg's timeout from the time left before the gather
+// deadline when the leg starts, so that legs get less time the later in a
+// request they run. The zero Budget leaves the leg the whole deadline.
+type Budget struct {
+	// Share is the fraction of the time left the leg may use. Zero means
+	// all of it.
+	Share float64
+	// Floor and Ceiling bound the share. The gather deadline still applies
+	// on top of Floor; a zero Ceiling means no upper bound.
+	Floor   time.Duration
+	Ceiling time.Duration
+	// MinUseful skips the leg, failing it with ErrBudgetExhausted, when less
+	// time than this is left, since a call that cannot finish would only
+	// add load. Zero always starts the leg.
+	MinUseful time.Duration
+}
+
+// Spec describes a single leg of a scatter-gather call.
+type Spec struct {
+	Name        string
+	Criticality Criticality
+	// Timeout bounds the leg on top of the gather context. Zero means the
+	// leg only inherits the gather deadline.
+	Timeout time.Duration
+	// Budget bounds the leg relative to the gather deadline. When both
+	// Timeout and Budget apply, the shorter one wins.
+	Budget Budget
+}
+
+// timeout returns the timeout of a leg starting with ctx, zero for none, or
+// false if the leg should not start at all.
+func (s Spec) timeout(ctx context.Context, now time.Time) (time.Duration, bool) {
+	timeout := s.Timeout
+	b := s.Budget
+	if b == (Budget{}) {
+		return timeout, true
+	}
+
+	budget := b.Ceiling
+	if deadline, ok := ctx.Deadline(); ok {
+		remaining := deadline.Sub(now)
+		if remaining < b.MinUseful {
+			return 0, false
+		}
+		share := b.Share
+		if share <= 0 || share > 1 {
+			share = 1
+		}
+		budget = max(time.Duration(float64(remaining)*share), b.Floor)
+		if b.Ceiling > 0 {
+			budget = min(budget, b.Ceiling)
+		}
+	}
+
+	if budget > 0 && (timeout <= 0 || budget < timeout) {
+		timeout = budget
+	}
+	return timeout, true
+}
+
+type Fetch[T any] func(ctx context.Context) (T, error)
+
+// Leg is a typed handle to a registered leg. Its value and error are only
+// meaningful after Gather.Run has returned.
+type Leg[T any] struct {
+	spec  Spec
+	fetch Fetch[T]
+
+	value   T
+	err     error
+	latency time.Duration
+}
+
+func (l *Leg[T]) Name() string {
+	return l.spec.Name
+}
+
+func (l *Leg[T]) Value() T {
+	return l.value
+}
+
+func (l *Leg[T]) Err() error {
+	return l.err
+}
+
+func (l *Leg[T]) Latency() time.Duration {
+	return l.latency
+}
+
+func (l *Leg[T]) legSpec() Spec {
+	return l.spec
+}
+
+func (l *Leg[T]) call(ctx context.Context) (any, error) {
+	return l.fetch(ctx)
+}
+
+func (l *Leg[T]) complete(value any, err error, latency time.Duration) {
+	l.err = err
+	l.latency = latency
+	if err != nil {
+		return
+	}
+	if v, ok := value.(T); ok {
+		l.value = v
+	}
+}
+
+type leg interface {
+	legSpec() Spec
+	call(ctx context.Context) (any, error)
+	complete(value any, err error, latency time.Duration)
+}
+
+// Gather fans out to all registered legs and collects their results.
+// A Gather is meant to be built and run once per request.
+type Gather struct {
+	legs []leg
+}
+
+func New() *Gather {
+	return &Gather{}
+}
+
+// Register adds a leg to g and returns a typed handle to its result.
+func Register[T any](g *Gather, spec Spec, fetch Fetch[T]) *Leg[T] {
+	l := &Leg[T]{spec: spec, fetch: fetch}
+	g.legs = append(g.legs, l)
+	return l
+}
+
+type LegError struct {
+	Leg string
+	Err error
+}
+
+func (e *LegError) Error() string {
+	return fmt.Sprintf("%s leg failed: %v", e.Leg, e.Err)
+}
+
+func (e *LegError) Unwrap() error {
+	return e.Err
+}
+
+type Outcome struct {
+	Name        string
+	Criticality Criticality
+	Status      Status
+	Err         error
+	Latency     time.Duration
+}
+
+type Report struct {
+	Degraded bool
+	Legs     []Outcome
+}
+
+type legResult struct {
+	index   int
+	value   any
+	err     error
+	latency time.Duration
+	cached  bool
+}
+
+// Run starts every leg concurrently and waits until all of them have
+// finished, a required leg has failed, or ctx is done. Legs that have not
+// finished by then are recorded with ctx.Err().
+//
+// The context handed to the legs is cancelled before Run returns, so a
+// failed required leg stops its siblings immediately and no leg that
+// honours its context keeps running once the caller has its result.
+func (g *Gather) Run(ctx context.Context) (*Report, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	start := time.Now()
+	results := make(chan legResult, len(g.legs))
+
+	for i, l := range g.legs {
+		go func(index int, l leg) {
+			timeout, ok := l.legSpec().timeout(ctx, time.Now())
+			if !ok {
+				results <- legResult{index: index, err: ErrBudgetExhausted}
+				return
+			}
+
+			state := &legState{}
+			legCtx := context.WithValue(ctx, legStateKey{}, state)
+			if timeout > 0 {
+				var cancel context.CancelFunc
+				legCtx, cancel = context.WithTimeout(legCtx, timeout)
+				defer cancel()
+			}
+
+			legCtx, span := startLegSpan(legCtx, l.legSpec())
+			legStart := time.Now()
+			value, err := l.call(legCtx)
+			endLegSpan(span, legStatus(err, state.cached.Load()), err)
+			results <- legResult{
+				index:   index,
+				value:   value,
+				err:     err,
+				latency: time.Since(legStart),
+				cached:  state.cached.Load(),
+			}
+		}(i, l)
+	}
+
+	report := &Report{Legs: make([]Outcome, len(g.legs))}
+	finished := make([]bool, len(g.legs))
+
+	for received := 0; received < len(g.legs); received++ {
+		select {
+		case result := <-results:
+			finished[result.index] = true
+			l := g.legs[result.index]
+			l.complete(result.value, result.err, result.latency)
+			report.record(result.index, l.legSpec(), legStatus(result.err, result.cached), result.err, result.latency)
+
+			if result.err != nil && l.legSpec().Criticality == Required {
+				cancel()
+				return g.abort(report, finished, StatusSkipped, context.Canceled, start), &LegError{Leg: l.legSpec().Name, Err: result.err}
+			}
+
+		case <-ctx.Done():
+			g.abort(report, finished, legStatus(ctx.Err(), false), ctx.Err(), start)
+			for i, l := range g.legs {
+				if !finished[i] && l.legSpec().Criticality == Required {
+					return report, &LegError{Leg: l.legSpec().Name, Err: ctx.Err()}
+				}
+			}
+			return report, nil
+		}
+	}
+
+	return report, nil
+}
+
+// abort records err for every leg that has not reported yet.
+func (g *Gather) abort(report *Report, finished []bool, status Status, err error, start time.Time) *Report {
+	latency := time.Since(start)
+	for i, l := range g.legs {
+		if finished[i] {
+			continue
+		}
+		l.complete(nil, err, latency)
+		report.record(i, l.legSpec(), status, err, latency)
+	}
+	return report
+}
+
+// startLegSpan starts a child span of the request span in ctx, if any.
+func startLegSpan(ctx context.Context, spec Spec) (context.Context, trace.Span) {
+	tracer := trace.SpanFromContext(ctx).TracerProvider().Tracer(tracing.InstrumentationName)
+	return tracer.Start(ctx, "scatter.leg "+spec.Name, trace.WithAttributes(
+		attribute.String("scatter.leg", spec.Name),
+		attribute.String("scatter.criticality", spec.Criticality.String()),
+	))
+}
+
+func endLegSpan(span trace.Span, status Status, err error) {
+	span.SetAttributes(attribute.String("scatter.status", string(status)))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func legStatus(err error, cached bool) Status {
+	switch {
+	case err == nil && cached:
+		return StatusCached
+	case err == nil:
+		return StatusOK
+	case errors.Is(err, ErrBudgetExhausted):
+		return StatusSkipped
+	case errors.Is(err, context.DeadlineExceeded), status.Code(err) == grpccodes.DeadlineExceeded:
+		// Backends report their own deadline as a gRPC status rather than
+		// the context error.
+		return StatusTimeout
+	default:
+		return StatusError
+	}
+}
+
+func (r *Report) record(index int, spec Spec, status Status, err error, latency time.Duration) {
+	r.Legs[index] = Outcome{
+		Name:        spec.Name,
+		Criticality: spec.Criticality,
+		Status:      status,
+		Err:         err,
+		Latency:     latency,
+	}
+	if err != nil && spec.Criticality == Optional {
+		r.Degraded = true
+	}
+}
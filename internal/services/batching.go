@@ -0,0 +1,64 @@
+package services
+
+import (
+	"context"
+
+	"github.com/vwency/resilient-scatter-gather/internal/microbatch"
+	pb_permissions "github.com/vwency/resilient-scatter-gather/proto/permissions"
+	pb_user "github.com/vwency/resilient-scatter-gather/proto/user"
+	pb_vector "github.com/vwency/resilient-scatter-gather/proto/vector"
+)
+
+var (
+	_ UserService         = (*UserServiceBatcher)(nil)
+	_ PermissionsService  = (*PermissionsServiceBatcher)(nil)
+	_ VectorMemoryService = (*VectorMemoryServiceBatcher)(nil)
+)
+
+// UserServiceBatcher turns concurrent GetUser calls into BatchGetUsers
+// calls on next.
+type UserServiceBatcher struct {
+	batcher *microbatch.Batcher[string, *pb_user.GetUserResponse]
+}
+
+func NewUserServiceBatcher(next UserBatchService, settings microbatch.Settings) *UserServiceBatcher {
+	return &UserServiceBatcher{
+		batcher: microbatch.New(settings, next.BatchGetUsers),
+	}
+}
+
+func (s *UserServiceBatcher) GetUser(ctx context.Context, userID string) (*pb_user.GetUserResponse, error) {
+	return s.batcher.Do(ctx, userID)
+}
+
+// PermissionsServiceBatcher turns concurrent CheckAccess calls into
+// BatchCheckAccess calls on next.
+type PermissionsServiceBatcher struct {
+	batcher *microbatch.Batcher[AccessCheck, *pb_permissions.CheckAccessResponse]
+}
+
+func NewPermissionsServiceBatcher(next PermissionsBatchService, settings microbatch.Settings) *PermissionsServiceBatcher {
+	return &PermissionsServiceBatcher{
+		batcher: microbatch.New(settings, next.BatchCheckAccess),
+	}
+}
+
+func (s *PermissionsServiceBatcher) CheckAccess(ctx context.Context, userID, resourceID string) (*pb_permissions.CheckAccessResponse, error) {
+	return s.batcher.Do(ctx, AccessCheck{UserID: userID, ResourceID: resourceID})
+}
+
+// VectorMemoryServiceBatcher turns concurrent GetContext calls into
+// BatchGetContext calls on next.
+type VectorMemoryServiceBatcher struct {
+	batcher *microbatch.Batcher[string, *pb_vector.GetContextResponse]
+}
+
+func NewVectorMemoryServiceBatcher(next VectorMemoryBatchService, settings microbatch.Settings) *VectorMemoryServiceBatcher {
+	return &VectorMemoryServiceBatcher{
+		batcher: microbatch.New(settings, next.BatchGetContext),
+	}
+}
+
+func (s *VectorMemoryServiceBatcher) GetContext(ctx context.Context, chatID string) (*pb_vector.GetContextResponse, error) {
+	return s.batcher.Do(ctx, chatID)
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/vwency/resilient-scatter-gather/internal/cache"
+	"github.com/vwency/resilient-scatter-gather/internal/scatter"
+	pb_permissions "github.com/vwency/resilient-scatter-gather/proto/permissions"
+	pb_user "github.com/vwency/resilient-scatter-gather/proto/user"
+)
+
+var (
+	_ UserService        = (*UserServiceCache)(nil)
+	_ PermissionsService = (*PermissionsServiceCache)(nil)
+)
+
+type UserServiceCache struct {
+	next  UserService
+	cache *cache.Cache[string, *pb_user.GetUserResponse]
+}
+
+func NewUserServiceCache(next UserService, settings cache.Settings) *UserServiceCache {
+	return &UserServiceCache{
+		next:  next,
+		cache: cache.New[string, *pb_user.GetUserResponse](settings),
+	}
+}
+
+func (s *UserServiceCache) GetUser(ctx context.Context, userID string) (*pb_user.GetUserResponse, error) {
+	resp, state, err := cache.Fetch(ctx, s.cache, userID, func(ctx context.Context) (*pb_user.GetUserResponse, error) {
+		return s.next.GetUser(ctx, userID)
+	})
+	if state != cache.Miss {
+		scatter.MarkCached(ctx)
+	}
+	return resp, err
+}
+
+type accessKey struct {
+	userID     string
+	resourceID string
+}
+
+// ErrStalePermissions is returned by NewPermissionsServiceCache for settings
+// that would serve access decisions past their TTL.
+var ErrStalePermissions = errors.New("permissions must not be served stale")
+
+// PermissionsServiceCache caches access decisions for at most TTL. They are
+// never served stale, so that a revoked grant stops working within TTL even
+// while the permissions service is down.
+type PermissionsServiceCache struct {
+	next  PermissionsService
+	cache *cache.Cache[accessKey, *pb_permissions.CheckAccessResponse]
+}
+
+func NewPermissionsServiceCache(next PermissionsService, settings cache.Settings) (*PermissionsServiceCache, error) {
+	if settings.StaleTTL > 0 {
+		return nil, ErrStalePermissions
+	}
+	return &PermissionsServiceCache{
+		next:  next,
+		cache: cache.New[accessKey, *pb_permissions.CheckAccessResponse](settings),
+	}, nil
+}
+
+func (s *PermissionsServiceCache) CheckAccess(ctx context.Context, userID, resourceID string) (*pb_permissions.CheckAccessResponse, error) {
+	key := accessKey{userID: userID, resourceID: resourceID}
+	resp, state, err := cache.Fetch(ctx, s.cache, key, func(ctx context.Context) (*pb_permissions.CheckAccessResponse, error) {
+		return s.next.CheckAccess(ctx, userID, resourceID)
+	})
+	if state != cache.Miss {
+		scatter.MarkCached(ctx)
+	}
+	return resp, err
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/vwency/resilient-scatter-gather/internal/breaker"
+	pb_permissions "github.com/vwency/resilient-scatter-gather/proto/permissions"
+	pb_user "github.com/vwency/resilient-scatter-gather/proto/user"
+	pb_vector "github.com/vwency/resilient-scatter-gather/proto/vector"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	_ UserService         = (*UserServiceBreaker)(nil)
+	_ PermissionsService  = (*PermissionsServiceBreaker)(nil)
+	_ VectorMemoryService = (*VectorMemoryServiceBreaker)(nil)
+)
+
+// recordOutcome reports err for a call admitted in generation to cb. Calls
+// their caller gave up on are released rather than recorded, so that a
+// cancelled probe cannot close the breaker.
+func recordOutcome(cb *breaker.CircuitBreaker, generation uint64, err error) {
+	if errors.Is(err, context.Canceled) || status.Code(err) == codes.Canceled {
+		cb.Release(generation)
+		return
+	}
+	cb.Record(generation, isBackendFailure(err))
+}
+
+type UserServiceBreaker struct {
+	next    UserService
+	breaker *breaker.CircuitBreaker
+}
+
+func NewUserServiceBreaker(next UserService, cb *breaker.CircuitBreaker) *UserServiceBreaker {
+	return &UserServiceBreaker{
+		next:    next,
+		breaker: cb,
+	}
+}
+
+func (s *UserServiceBreaker) GetUser(ctx context.Context, userID string) (*pb_user.GetUserResponse, error) {
+	generation, err := s.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.next.GetUser(ctx, userID)
+	recordOutcome(s.breaker, generation, err)
+
+	return resp, err
+}
+
+type PermissionsServiceBreaker struct {
+	next    PermissionsService
+	breaker *breaker.CircuitBreaker
+}
+
+func NewPermissionsServiceBreaker(next PermissionsService, cb *breaker.CircuitBreaker) *PermissionsServiceBreaker {
+	return &PermissionsServiceBreaker{
+		next:    next,
+		breaker: cb,
+	}
+}
+
+func (s *PermissionsServiceBreaker) CheckAccess(ctx context.Context, userID, resourceID string) (*pb_permissions.CheckAccessResponse, error) {
+	generation, err := s.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.next.CheckAccess(ctx, userID, resourceID)
+	recordOutcome(s.breaker, generation, err)
+
+	return resp, err
+}
+
+type VectorMemoryServiceBreaker struct {
+	next    VectorMemoryService
+	breaker *breaker.CircuitBreaker
+}
+
+func NewVectorMemoryServiceBreaker(next VectorMemoryService, cb *breaker.CircuitBreaker) *VectorMemoryServiceBreaker {
+	return &VectorMemoryServiceBreaker{
+		next:    next,
+		breaker: cb,
+	}
+}
+
+func (s *VectorMemoryServiceBreaker) GetContext(ctx context.Context, chatID string) (*pb_vector.GetContextResponse, error) {
+	generation, err := s.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.next.GetContext(ctx, chatID)
+	recordOutcome(s.breaker, generation, err)
+
+	return resp, err
+}
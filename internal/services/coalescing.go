@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+
+	"github.com/vwency/resilient-scatter-gather/internal/coalesce"
+	pb_permissions "github.com/vwency/resilient-scatter-gather/proto/permissions"
+	pb_user "github.com/vwency/resilient-scatter-gather/proto/user"
+	pb_vector "github.com/vwency/resilient-scatter-gather/proto/vector"
+)
+
+var (
+	_ UserService         = (*UserServiceCoalescer)(nil)
+	_ PermissionsService  = (*PermissionsServiceCoalescer)(nil)
+	_ VectorMemoryService = (*VectorMemoryServiceCoalescer)(nil)
+)
+
+type UserServiceCoalescer struct {
+	next  UserService
+	group *coalesce.Group[string, *pb_user.GetUserResponse]
+}
+
+func NewUserServiceCoalescer(next UserService) *UserServiceCoalescer {
+	return &UserServiceCoalescer{
+		next:  next,
+		group: coalesce.NewGroup[string, *pb_user.GetUserResponse](),
+	}
+}
+
+func (s *UserServiceCoalescer) GetUser(ctx context.Context, userID string) (*pb_user.GetUserResponse, error) {
+	resp, _, err := s.group.Do(ctx, userID, func(ctx context.Context) (*pb_user.GetUserResponse, error) {
+		return s.next.GetUser(ctx, userID)
+	})
+	return resp, err
+}
+
+type PermissionsServiceCoalescer struct {
+	next  PermissionsService
+	group *coalesce.Group[accessKey, *pb_permissions.CheckAccessResponse]
+}
+
+func NewPermissionsServiceCoalescer(next PermissionsService) *PermissionsServiceCoalescer {
+	return &PermissionsServiceCoalescer{
+		next:  next,
+		group: coalesce.NewGroup[accessKey, *pb_permissions.CheckAccessResponse](),
+	}
+}
+
+func (s *PermissionsServiceCoalescer) CheckAccess(ctx context.Context, userID, resourceID string) (*pb_permissions.CheckAccessResponse, error) {
+	key := accessKey{userID: userID, resourceID: resourceID}
+	resp, _, err := s.group.Do(ctx, key, func(ctx context.Context) (*pb_permissions.CheckAccessResponse, error) {
+		return s.next.CheckAccess(ctx, userID, resourceID)
+	})
+	return resp, err
+}
+
+type VectorMemoryServiceCoalescer struct {
+	next  VectorMemoryService
+	group *coalesce.Group[string, *pb_vector.GetContextResponse]
+}
+
+func NewVectorMemoryServiceCoalescer(next VectorMemoryService) *VectorMemoryServiceCoalescer {
+	return &VectorMemoryServiceCoalescer{
+		next:  next,
+		group: coalesce.NewGroup[string, *pb_vector.GetContextResponse](),
+	}
+}
+
+func (s *VectorMemoryServiceCoalescer) GetContext(ctx context.Context, chatID string) (*pb_vector.GetContextResponse, error) {
+	resp, _, err := s.group.Do(ctx, chatID, func(ctx context.Context) (*pb_vector.GetContextResponse, error) {
+		return s.next.GetContext(ctx, chatID)
+	})
+	return resp, err
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	statuspb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	ErrTimeout         = errors.New("backend timeout")
+	ErrUnavailable     = errors.New("backend unavailable")
+	ErrInvalidArgument = errors.New("invalid argument")
+)
+
+// BackendError is returned by the gRPC clients for every failed call. It
+// matches ErrTimeout, ErrUnavailable and ErrInvalidArgument with errors.Is
+// and keeps the gRPC code visible to status.Code.
+type BackendError struct {
+	Service string
+	Code    codes.Code
+	Err     error
+}
+
+func (e *BackendError) Error() string {
+	return fmt.Sprintf("%s: %s: %v", e.Service, e.Code, e.Err)
+}
+
+func (e *BackendError) Unwrap() error {
+	return e.Err
+}
+
+func (e *BackendError) GRPCStatus() *status.Status {
+	return status.New(e.Code, e.Error())
+}
+
+func (e *BackendError) Is(target error) bool {
+	switch target {
+	case ErrTimeout:
+		return e.Code == codes.DeadlineExceeded
+	case ErrUnavailable:
+		return e.Code == codes.Unavailable
+	case ErrInvalidArgument:
+		return e.Code == codes.InvalidArgument
+	}
+	return false
+}
+
+func newBackendError(service string, err error) *BackendError {
+	code := status.Code(err)
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		code = status.FromContextError(err).Code()
+	}
+
+	return &BackendError{
+		Service: service,
+		Code:    code,
+		Err:     err,
+	}
+}
+
+// isBackendFailure reports whether err says something about the health of
+// the backend, as opposed to a rejected request or a caller that gave up.
+func isBackendFailure(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	switch status.Code(err) {
+	case codes.Canceled,
+		codes.InvalidArgument,
+		codes.NotFound,
+		codes.AlreadyExists,
+		codes.PermissionDenied,
+		codes.Unauthenticated,
+		codes.FailedPrecondition,
+		codes.OutOfRange:
+		return false
+	}
+
+	return true
+}
+
+func logBackendError(ctx context.Context, logger *slog.Logger, method string, err *BackendError) {
+	level := slog.LevelWarn
+	if !isBackendFailure(err) {
+		level = slog.LevelDebug
+	}
+
+	logger.Log(ctx, level, "backend call failed",
+		"service", err.Service, "method", method, "code", err.Code.String(), "error", err.Err)
+}
+
+// checkBatchSize rejects batch responses that do not hold one result per
+// requested item, since results are matched to items by position.
+func checkBatchSize(method string, want, got int) error {
+	if got != want {
+		return status.Errorf(codes.Internal, "%s returned %d results for %d items", method, got, want)
+	}
+	return nil
+}
+
+// batchItemErrors turns the per-item statuses of a batch response into one
+// error per item, nil for the items that succeeded, and logs the failures.
+// Empty statuses mean every item succeeded and give nil errs.
+func batchItemErrors(ctx context.Context, logger *slog.Logger, service, method string, want int, statuses []*statuspb.Status) ([]error, error) {
+	if len(statuses) == 0 {
+		return nil, nil
+	}
+	if len(statuses) != want {
+		return nil, status.Errorf(codes.Internal, "%s returned %d statuses for %d items", method, len(statuses), want)
+	}
+
+	errs := make([]error, want)
+	for i, st := range statuses {
+		if codes.Code(st.GetCode()) == codes.OK {
+			continue
+		}
+		backendErr := newBackendError(service, status.FromProto(st).Err())
+		logBackendError(ctx, logger, method, backendErr)
+		errs[i] = backendErr
+	}
+	return errs, nil
+}
+
+// batchItemStatus is the status of one item of a batch response.
+func batchItemStatus(err error) *statuspb.Status {
+	if err == nil {
+		return &statuspb.Status{}
+	}
+	return status.Convert(err).Proto()
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"context"
+
+	"github.com/vwency/resilient-scatter-gather/internal/hedge"
+	pb_permissions "github.com/vwency/resilient-scatter-gather/proto/permissions"
+	pb_user "github.com/vwency/resilient-scatter-gather/proto/user"
+	pb_vector "github.com/vwency/resilient-scatter-gather/proto/vector"
+)
+
+var (
+	_ UserService         = (*UserServiceHedger)(nil)
+	_ PermissionsService  = (*PermissionsServiceHedger)(nil)
+	_ VectorMemoryService = (*VectorMemoryServiceHedger)(nil)
+)
+
+type UserServiceHedger struct {
+	next   UserService
+	hedger *hedge.Hedger
+}
+
+func NewUserServiceHedger(next UserService, h *hedge.Hedger) *UserServiceHedger {
+	return &UserServiceHedger{
+		next:   next,
+		hedger: h,
+	}
+}
+
+func (s *UserServiceHedger) GetUser(ctx context.Context, userID string) (*pb_user.GetUserResponse, error) {
+	return hedge.Do(ctx, s.hedger, func(ctx context.Context) (*pb_user.GetUserResponse, error) {
+		return s.next.GetUser(ctx, userID)
+	})
+}
+
+type PermissionsServiceHedger struct {
+	next   PermissionsService
+	hedger *hedge.Hedger
+}
+
+func NewPermissionsServiceHedger(next PermissionsService, h *hedge.Hedger) *PermissionsServiceHedger {
+	return &PermissionsServiceHedger{
+		next:   next,
+		hedger: h,
+	}
+}
+
+func (s *PermissionsServiceHedger) CheckAccess(ctx context.Context, userID, resourceID string) (*pb_permissions.CheckAccessResponse, error) {
+	return hedge.Do(ctx, s.hedger, func(ctx context.Context) (*pb_permissions.CheckAccessResponse, error) {
+		return s.next.CheckAccess(ctx, userID, resourceID)
+	})
+}
+
+type VectorMemoryServiceHedger struct {
+	next   VectorMemoryService
+	hedger *hedge.Hedger
+}
+
+func NewVectorMemoryServiceHedger(next VectorMemoryService, h *hedge.Hedger) *VectorMemoryServiceHedger {
+	return &VectorMemoryServiceHedger{
+		next:   next,
+		hedger: h,
+	}
+}
+
+func (s *VectorMemoryServiceHedger) GetContext(ctx context.Context, chatID string) (*pb_vector.GetContextResponse, error) {
+	return hedge.Do(ctx, s.hedger, func(ctx context.Context) (*pb_vector.GetContextResponse, error) {
+		return s.next.GetContext(ctx, chatID)
+	})
+}
@@ -19,3 +19,30 @@ type PermissionsService interface {
 type VectorMemoryService interface {
 	GetContext(ctx context.Context, chatID string) (*pb_vector.GetContextResponse, error)
 }
+
+// UserBatchService fetches many users in one call. It returns one user per
+// ID, in the order of userIDs, and errs with the failure of each ID that
+// failed on its own, or nil if none did.
+type UserBatchService interface {
+	BatchGetUsers(ctx context.Context, userIDs []string) (users []*pb_user.GetUserResponse, errs []error, err error)
+}
+
+// AccessCheck is one check of a PermissionsBatchService call.
+type AccessCheck struct {
+	UserID     string
+	ResourceID string
+}
+
+// PermissionsBatchService runs many access checks in one call. It returns
+// one result per check, in the order of checks, and errs like
+// UserBatchService.
+type PermissionsBatchService interface {
+	BatchCheckAccess(ctx context.Context, checks []AccessCheck) (results []*pb_permissions.CheckAccessResponse, errs []error, err error)
+}
+
+// VectorMemoryBatchService fetches the context of many chats in one call. It
+// returns one context per chat, in the order of chatIDs, and errs like
+// UserBatchService.
+type VectorMemoryBatchService interface {
+	BatchGetContext(ctx context.Context, chatIDs []string) (contexts []*pb_vector.GetContextResponse, errs []error, err error)
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/vwency/resilient-scatter-gather/internal/metrics"
+	pb_permissions "github.com/vwency/resilient-scatter-gather/proto/permissions"
+	pb_user "github.com/vwency/resilient-scatter-gather/proto/user"
+	pb_vector "github.com/vwency/resilient-scatter-gather/proto/vector"
+)
+
+var (
+	_ UserService         = (*UserServiceMetrics)(nil)
+	_ PermissionsService  = (*PermissionsServiceMetrics)(nil)
+	_ VectorMemoryService = (*VectorMemoryServiceMetrics)(nil)
+)
+
+type UserServiceMetrics struct {
+	next    UserService
+	metrics *metrics.Metrics
+}
+
+func NewUserServiceMetrics(next UserService, m *metrics.Metrics) *UserServiceMetrics {
+	return &UserServiceMetrics{
+		next:    next,
+		metrics: m,
+	}
+}
+
+func (s *UserServiceMetrics) GetUser(ctx context.Context, userID string) (*pb_user.GetUserResponse, error) {
+	s.metrics.BackendCallStarted("UserService")
+	start := time.Now()
+
+	resp, err := s.next.GetUser(ctx, userID)
+	s.metrics.BackendCallFinished("UserService", time.Since(start), err)
+
+	return resp, err
+}
+
+type PermissionsServiceMetrics struct {
+	next    PermissionsService
+	metrics *metrics.Metrics
+}
+
+func NewPermissionsServiceMetrics(next PermissionsService, m *metrics.Metrics) *PermissionsServiceMetrics {
+	return &PermissionsServiceMetrics{
+		next:    next,
+		metrics: m,
+	}
+}
+
+func (s *PermissionsServiceMetrics) CheckAccess(ctx context.Context, userID, resourceID string) (*pb_permissions.CheckAccessResponse, error) {
+	s.metrics.BackendCallStarted("PermissionsService")
+	start := time.Now()
+
+	resp, err := s.next.CheckAccess(ctx, userID, resourceID)
+	s.metrics.BackendCallFinished("PermissionsService", time.Since(start), err)
+
+	return resp, err
+}
+
+type VectorMemoryServiceMetrics struct {
+	next    VectorMemoryService
+	metrics *metrics.Metrics
+}
+
+func NewVectorMemoryServiceMetrics(next VectorMemoryService, m *metrics.Metrics) *VectorMemoryServiceMetrics {
+	return &VectorMemoryServiceMetrics{
+		next:    next,
+		metrics: m,
+	}
+}
+
+func (s *VectorMemoryServiceMetrics) GetContext(ctx context.Context, chatID string) (*pb_vector.GetContextResponse, error) {
+	s.metrics.BackendCallStarted("VectorMemoryService")
+	start := time.Now()
+
+	resp, err := s.next.GetContext(ctx, chatID)
+	s.metrics.BackendCallFinished("VectorMemoryService", time.Since(start), err)
+
+	return resp, err
+}
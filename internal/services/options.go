@@ -0,0 +1,24 @@
+package services
+
+import "log/slog"
+
+// ClientOption configures the gRPC service clients.
+type ClientOption func(o *clientOptions)
+
+type clientOptions struct {
+	logger *slog.Logger
+}
+
+func newClientOptions(opts []ClientOption) clientOptions {
+	o := clientOptions{logger: slog.Default()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+func WithLogger(logger *slog.Logger) ClientOption {
+	return func(o *clientOptions) {
+		o.logger = logger
+	}
+}
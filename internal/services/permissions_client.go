@@ -2,30 +2,34 @@ package services
 
 import (
 	"context"
-	"time"
+	"log/slog"
 
+	"github.com/vwency/resilient-scatter-gather/internal/requestid"
+	"github.com/vwency/resilient-scatter-gather/internal/tracing"
 	pb "github.com/vwency/resilient-scatter-gather/proto/permissions"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 )
 
-var _ PermissionsService = (*PermissionsServiceClient)(nil)
+var (
+	_ PermissionsService      = (*PermissionsServiceClient)(nil)
+	_ PermissionsBatchService = (*PermissionsServiceClient)(nil)
+)
 
 type PermissionsServiceClient struct {
-	client             pb.PermissionsServiceClient
-	degradationTimeout time.Duration
+	client pb.PermissionsServiceClient
+	logger *slog.Logger
 }
 
-func NewPermissionsServiceClient(client pb.PermissionsServiceClient, degradationTimeout time.Duration) *PermissionsServiceClient {
+func NewPermissionsServiceClient(client pb.PermissionsServiceClient, opts ...ClientOption) *PermissionsServiceClient {
+	o := newClientOptions(opts)
 	return &PermissionsServiceClient{
-		client:             client,
-		degradationTimeout: degradationTimeout,
+		client: client,
+		logger: o.logger,
 	}
 }
 
 func (s *PermissionsServiceClient) CheckAccess(ctx context.Context, userID, resourceID string) (*pb.CheckAccessResponse, error) {
-	ctx, cancel := context.WithTimeout(ctx, s.degradationTimeout)
-	defer cancel()
+	ctx, span := tracing.StartClientSpan(ctx, "PermissionsService", "CheckAccess")
+	ctx = requestid.AppendToOutgoing(ctx)
 
 	req := &pb.CheckAccessRequest{
 		UserId:     userID,
@@ -34,16 +38,47 @@ func (s *PermissionsServiceClient) CheckAccess(ctx context.Context, userID, reso
 	}
 
 	resp, err := s.client.CheckAccess(ctx, req)
+	tracing.EndClientSpan(span, err)
 	if err != nil {
-		if err == context.DeadlineExceeded {
-			return nil, status.Error(codes.Internal, "Permissions service timeout")
-		}
-		return nil, err
+		backendErr := newBackendError("PermissionsService", err)
+		logBackendError(ctx, s.logger, "CheckAccess", backendErr)
+		return nil, backendErr
 	}
 
 	return resp, nil
 }
 
+func (s *PermissionsServiceClient) BatchCheckAccess(ctx context.Context, checks []AccessCheck) ([]*pb.CheckAccessResponse, []error, error) {
+	ctx, span := tracing.StartClientSpan(ctx, "PermissionsService", "BatchCheckAccess")
+	ctx = requestid.AppendToOutgoing(ctx)
+
+	req := &pb.BatchCheckAccessRequest{Checks: make([]*pb.CheckAccessRequest, 0, len(checks))}
+	for _, check := range checks {
+		req.Checks = append(req.Checks, &pb.CheckAccessRequest{
+			UserId:     check.UserID,
+			ResourceId: check.ResourceID,
+			Action:     "read",
+		})
+	}
+
+	resp, err := s.client.BatchCheckAccess(ctx, req)
+	var errs []error
+	if err == nil {
+		err = checkBatchSize("BatchCheckAccess", len(checks), len(resp.GetResults()))
+	}
+	if err == nil {
+		errs, err = batchItemErrors(ctx, s.logger, "PermissionsService", "BatchCheckAccess", len(checks), resp.GetStatuses())
+	}
+	tracing.EndClientSpan(span, err)
+	if err != nil {
+		backendErr := newBackendError("PermissionsService", err)
+		logBackendError(ctx, s.logger, "BatchCheckAccess", backendErr)
+		return nil, nil, backendErr
+	}
+
+	return resp.GetResults(), errs, nil
+}
+
 type PermissionsServiceServer struct {
 	pb.UnimplementedPermissionsServiceServer
 }
@@ -63,3 +98,16 @@ func (s *PermissionsServiceServer) CheckAccess(ctx context.Context, req *pb.Chec
 		Reason: "User has full access to the chat",
 	}, nil
 }
+
+func (s *PermissionsServiceServer) BatchCheckAccess(ctx context.Context, req *pb.BatchCheckAccessRequest) (*pb.BatchCheckAccessResponse, error) {
+	resp := &pb.BatchCheckAccessResponse{}
+	for _, check := range req.Checks {
+		result, err := s.CheckAccess(ctx, check)
+		if err != nil {
+			result = &pb.CheckAccessResponse{}
+		}
+		resp.Results = append(resp.Results, result)
+		resp.Statuses = append(resp.Statuses, batchItemStatus(err))
+	}
+	return resp, nil
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"context"
+
+	"github.com/vwency/resilient-scatter-gather/internal/retry"
+	pb_permissions "github.com/vwency/resilient-scatter-gather/proto/permissions"
+	pb_user "github.com/vwency/resilient-scatter-gather/proto/user"
+	pb_vector "github.com/vwency/resilient-scatter-gather/proto/vector"
+)
+
+var (
+	_ UserService         = (*UserServiceRetrier)(nil)
+	_ PermissionsService  = (*PermissionsServiceRetrier)(nil)
+	_ VectorMemoryService = (*VectorMemoryServiceRetrier)(nil)
+)
+
+type UserServiceRetrier struct {
+	next    UserService
+	retrier *retry.Retrier
+}
+
+func NewUserServiceRetrier(next UserService, r *retry.Retrier) *UserServiceRetrier {
+	return &UserServiceRetrier{
+		next:    next,
+		retrier: r,
+	}
+}
+
+func (s *UserServiceRetrier) GetUser(ctx context.Context, userID string) (*pb_user.GetUserResponse, error) {
+	return retry.Do(ctx, s.retrier, func(ctx context.Context) (*pb_user.GetUserResponse, error) {
+		return s.next.GetUser(ctx, userID)
+	})
+}
+
+type PermissionsServiceRetrier struct {
+	next    PermissionsService
+	retrier *retry.Retrier
+}
+
+func NewPermissionsServiceRetrier(next PermissionsService, r *retry.Retrier) *PermissionsServiceRetrier {
+	return &PermissionsServiceRetrier{
+		next:    next,
+		retrier: r,
+	}
+}
+
+func (s *PermissionsServiceRetrier) CheckAccess(ctx context.Context, userID, resourceID string) (*pb_permissions.CheckAccessResponse, error) {
+	return retry.Do(ctx, s.retrier, func(ctx context.Context) (*pb_permissions.CheckAccessResponse, error) {
+		return s.next.CheckAccess(ctx, userID, resourceID)
+	})
+}
+
+type VectorMemoryServiceRetrier struct {
+	next    VectorMemoryService
+	retrier *retry.Retrier
+}
+
+func NewVectorMemoryServiceRetrier(next VectorMemoryService, r *retry.Retrier) *VectorMemoryServiceRetrier {
+	return &VectorMemoryServiceRetrier{
+		next:    next,
+		retrier: r,
+	}
+}
+
+func (s *VectorMemoryServiceRetrier) GetContext(ctx context.Context, chatID string) (*pb_vector.GetContextResponse, error) {
+	return retry.Do(ctx, s.retrier, func(ctx context.Context) (*pb_vector.GetContextResponse, error) {
+		return s.next.GetContext(ctx, chatID)
+	})
+}
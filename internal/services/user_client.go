@@ -2,43 +2,70 @@ package services
 
 import (
 	"context"
-	"time"
+	"log/slog"
 
+	"github.com/vwency/resilient-scatter-gather/internal/requestid"
+	"github.com/vwency/resilient-scatter-gather/internal/tracing"
 	pb "github.com/vwency/resilient-scatter-gather/proto/user"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 )
 
-var _ UserService = (*UserServiceClient)(nil)
+var (
+	_ UserService      = (*UserServiceClient)(nil)
+	_ UserBatchService = (*UserServiceClient)(nil)
+)
 
 type UserServiceClient struct {
-	client             pb.UserServiceClient
-	degradationTimeout time.Duration
+	client pb.UserServiceClient
+	logger *slog.Logger
 }
 
-func NewUserServiceClient(client pb.UserServiceClient, degradationTimeout time.Duration) *UserServiceClient {
+func NewUserServiceClient(client pb.UserServiceClient, opts ...ClientOption) *UserServiceClient {
+	o := newClientOptions(opts)
 	return &UserServiceClient{
-		client:             client,
-		degradationTimeout: degradationTimeout,
+		client: client,
+		logger: o.logger,
 	}
 }
 
 func (s *UserServiceClient) GetUser(ctx context.Context, userID string) (*pb.GetUserResponse, error) {
-	ctx, cancel := context.WithTimeout(ctx, s.degradationTimeout)
-	defer cancel()
+	ctx, span := tracing.StartClientSpan(ctx, "UserService", "GetUser")
+	ctx = requestid.AppendToOutgoing(ctx)
 
 	req := &pb.GetUserRequest{UserId: userID}
 	resp, err := s.client.GetUser(ctx, req)
+	tracing.EndClientSpan(span, err)
 	if err != nil {
-		if err == context.DeadlineExceeded {
-			return nil, status.Error(codes.Internal, "User service timeout")
-		}
-		return nil, err
+		backendErr := newBackendError("UserService", err)
+		logBackendError(ctx, s.logger, "GetUser", backendErr)
+		return nil, backendErr
 	}
 
 	return resp, nil
 }
 
+func (s *UserServiceClient) BatchGetUsers(ctx context.Context, userIDs []string) ([]*pb.GetUserResponse, []error, error) {
+	ctx, span := tracing.StartClientSpan(ctx, "UserService", "BatchGetUsers")
+	ctx = requestid.AppendToOutgoing(ctx)
+
+	req := &pb.BatchGetUsersRequest{UserIds: userIDs}
+	resp, err := s.client.BatchGetUsers(ctx, req)
+	var errs []error
+	if err == nil {
+		err = checkBatchSize("BatchGetUsers", len(userIDs), len(resp.GetUsers()))
+	}
+	if err == nil {
+		errs, err = batchItemErrors(ctx, s.logger, "UserService", "BatchGetUsers", len(userIDs), resp.GetStatuses())
+	}
+	tracing.EndClientSpan(span, err)
+	if err != nil {
+		backendErr := newBackendError("UserService", err)
+		logBackendError(ctx, s.logger, "BatchGetUsers", backendErr)
+		return nil, nil, backendErr
+	}
+
+	return resp.GetUsers(), errs, nil
+}
+
 type UserServiceServer struct {
 	pb.UnimplementedUserServiceServer
 }
@@ -55,3 +82,16 @@ func (s *UserServiceServer) GetUser(ctx context.Context, req *pb.GetUserRequest)
 		Role:     "",
 	}, nil
 }
+
+func (s *UserServiceServer) BatchGetUsers(ctx context.Context, req *pb.BatchGetUsersRequest) (*pb.BatchGetUsersResponse, error) {
+	resp := &pb.BatchGetUsersResponse{}
+	for _, userID := range req.UserIds {
+		user, err := s.GetUser(ctx, &pb.GetUserRequest{UserId: userID})
+		if err != nil {
+			user = &pb.GetUserResponse{}
+		}
+		resp.Users = append(resp.Users, user)
+		resp.Statuses = append(resp.Statuses, batchItemStatus(err))
+	}
+	return resp, nil
+}
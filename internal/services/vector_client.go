@@ -2,28 +2,34 @@ package services
 
 import (
 	"context"
-	"time"
+	"log/slog"
 
+	"github.com/vwency/resilient-scatter-gather/internal/requestid"
+	"github.com/vwency/resilient-scatter-gather/internal/tracing"
 	pb "github.com/vwency/resilient-scatter-gather/proto/vector"
 )
 
-var _ VectorMemoryService = (*VectorMemoryServiceClient)(nil)
+var (
+	_ VectorMemoryService      = (*VectorMemoryServiceClient)(nil)
+	_ VectorMemoryBatchService = (*VectorMemoryServiceClient)(nil)
+)
 
 type VectorMemoryServiceClient struct {
-	client             pb.VectorMemoryServiceClient
-	degradationTimeout time.Duration
+	client pb.VectorMemoryServiceClient
+	logger *slog.Logger
 }
 
-func NewVectorMemoryServiceClient(client pb.VectorMemoryServiceClient, degradationTimeout time.Duration) *VectorMemoryServiceClient {
+func NewVectorMemoryServiceClient(client pb.VectorMemoryServiceClient, opts ...ClientOption) *VectorMemoryServiceClient {
+	o := newClientOptions(opts)
 	return &VectorMemoryServiceClient{
-		client:             client,
-		degradationTimeout: degradationTimeout,
+		client: client,
+		logger: o.logger,
 	}
 }
 
 func (s *VectorMemoryServiceClient) GetContext(ctx context.Context, chatID string) (*pb.GetContextResponse, error) {
-	ctx, cancel := context.WithTimeout(ctx, s.degradationTimeout)
-	defer cancel()
+	ctx, span := tracing.StartClientSpan(ctx, "VectorMemoryService", "GetContext")
+	ctx = requestid.AppendToOutgoing(ctx)
 
 	req := &pb.GetContextRequest{
 		ChatId: chatID,
@@ -31,16 +37,46 @@ func (s *VectorMemoryServiceClient) GetContext(ctx context.Context, chatID strin
 	}
 
 	resp, err := s.client.GetContext(ctx, req)
+	tracing.EndClientSpan(span, err)
 	if err != nil {
-		return &pb.GetContextResponse{
-			Items:      []*pb.ContextItem{},
-			TotalCount: 0,
-		}, nil
+		backendErr := newBackendError("VectorMemoryService", err)
+		logBackendError(ctx, s.logger, "GetContext", backendErr)
+		return nil, backendErr
 	}
 
 	return resp, nil
 }
 
+func (s *VectorMemoryServiceClient) BatchGetContext(ctx context.Context, chatIDs []string) ([]*pb.GetContextResponse, []error, error) {
+	ctx, span := tracing.StartClientSpan(ctx, "VectorMemoryService", "BatchGetContext")
+	ctx = requestid.AppendToOutgoing(ctx)
+
+	req := &pb.BatchGetContextRequest{Requests: make([]*pb.GetContextRequest, 0, len(chatIDs))}
+	for _, chatID := range chatIDs {
+		req.Requests = append(req.Requests, &pb.GetContextRequest{
+			ChatId: chatID,
+			Limit:  10,
+		})
+	}
+
+	resp, err := s.client.BatchGetContext(ctx, req)
+	var errs []error
+	if err == nil {
+		err = checkBatchSize("BatchGetContext", len(chatIDs), len(resp.GetContexts()))
+	}
+	if err == nil {
+		errs, err = batchItemErrors(ctx, s.logger, "VectorMemoryService", "BatchGetContext", len(chatIDs), resp.GetStatuses())
+	}
+	tracing.EndClientSpan(span, err)
+	if err != nil {
+		backendErr := newBackendError("VectorMemoryService", err)
+		logBackendError(ctx, s.logger, "BatchGetContext", backendErr)
+		return nil, nil, backendErr
+	}
+
+	return resp.GetContexts(), errs, nil
+}
+
 type VectorMemoryServiceServer struct {
 	pb.UnimplementedVectorMemoryServiceServer
 }
@@ -55,3 +91,16 @@ func (s *VectorMemoryServiceServer) GetContext(ctx context.Context, req *pb.GetC
 		TotalCount: 0,
 	}, nil
 }
+
+func (s *VectorMemoryServiceServer) BatchGetContext(ctx context.Context, req *pb.BatchGetContextRequest) (*pb.BatchGetContextResponse, error) {
+	resp := &pb.BatchGetContextResponse{}
+	for _, r := range req.Requests {
+		item, err := s.GetContext(ctx, r)
+		if err != nil {
+			item = &pb.GetContextResponse{}
+		}
+		resp.Contexts = append(resp.Contexts, item)
+		resp.Statuses = append(resp.Statuses, batchItemStatus(err))
+	}
+	return resp, nil
+}
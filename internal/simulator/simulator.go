@@ -0,0 +1,214 @@
+package simulator
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Distribution is the shape latencies are drawn from.
+type Distribution string
+
+const (
+	// Constant always waits Mean.
+	Constant Distribution = "constant"
+	// Uniform waits between Min and Max.
+	Uniform Distribution = "uniform"
+	// Normal waits Mean ± StdDev, clamped to [Min, Max].
+	Normal Distribution = "normal"
+	// Exponential waits Min plus an exponentially distributed delay with
+	// mean Mean, clamped to Max. It produces the long tail real backends
+	// have.
+	Exponential Distribution = "exponential"
+)
+
+func ParseDistribution(s string) (Distribution, error) {
+	switch d := Distribution(strings.ToLower(s)); d {
+	case "":
+		return Constant, nil
+	case Constant, Uniform, Normal, Exponential:
+		return d, nil
+	default:
+		return "", fmt.Errorf("unknown latency distribution %q", s)
+	}
+}
+
+type Latency struct {
+	Distribution Distribution
+	Mean         time.Duration
+	StdDev       time.Duration
+	Min          time.Duration
+	// Max caps sampled latencies; zero means no cap.
+	Max time.Duration
+}
+
+type Method struct {
+	Latency Latency
+	// ErrorRate is the fraction of calls, between 0 and 1, that fail with
+	// ErrorCode after their latency has elapsed.
+	ErrorRate float64
+	ErrorCode codes.Code
+}
+
+// Outage makes every call fail with Code, without any latency, between
+// Start and Start+Duration after the simulator started. A positive Every
+// repeats the window with that period.
+type Outage struct {
+	Start    time.Duration
+	Duration time.Duration
+	Every    time.Duration
+	Code     codes.Code
+}
+
+func (o Outage) activeAt(elapsed time.Duration) bool {
+	if elapsed < o.Start {
+		return false
+	}
+	offset := elapsed - o.Start
+	if o.Every > 0 {
+		offset %= o.Every
+	}
+	return offset < o.Duration
+}
+
+type Settings struct {
+	// Methods is keyed by the unqualified, case-insensitive method name,
+	// e.g. "GetUser". Methods without an entry are passed through.
+	Methods map[string]Method
+	Outages []Outage
+}
+
+// Injector applies Settings to the calls of a single gRPC service.
+type Injector struct {
+	methods map[string]Method
+	outages []Outage
+	start   time.Time
+	now     func() time.Time
+
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+func New(settings Settings) *Injector {
+	return NewWithSource(settings, time.Now, rand.New(rand.NewPCG(uint64(time.Now().UnixNano()), 0)))
+}
+
+// NewWithSource samples latencies and errors from rng and places outage
+// windows by now, so a seeded rng and a fixed clock replay the same faults.
+func NewWithSource(settings Settings, now func() time.Time, rng *rand.Rand) *Injector {
+	methods := make(map[string]Method, len(settings.Methods))
+	for name, m := range settings.Methods {
+		methods[strings.ToLower(name)] = m
+	}
+
+	return &Injector{
+		methods: methods,
+		outages: settings.Outages,
+		start:   now(),
+		now:     now,
+		rng:     rng,
+	}
+}
+
+// ActiveOutage returns the outage in effect right now, if any.
+func (i *Injector) ActiveOutage() (Outage, bool) {
+	elapsed := i.now().Sub(i.start)
+	for _, o := range i.outages {
+		if o.activeAt(elapsed) {
+			return o, true
+		}
+	}
+	return Outage{}, false
+}
+
+// Plan decides what happens to the next call of method: how long it is
+// delayed and which error, if any, it ends with.
+func (i *Injector) Plan(method string) (time.Duration, error) {
+	if o, ok := i.ActiveOutage(); ok {
+		return 0, status.Errorf(o.Code, "simulated outage of %s", method)
+	}
+
+	m, ok := i.methods[strings.ToLower(method)]
+	if !ok {
+		return 0, nil
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	delay := i.sampleLatency(m.Latency)
+	if m.ErrorRate > 0 && i.rng.Float64() < m.ErrorRate {
+		return delay, status.Errorf(m.ErrorCode, "simulated %s failure", method)
+	}
+	return delay, nil
+}
+
+func (i *Injector) sampleLatency(l Latency) time.Duration {
+	var d time.Duration
+	switch l.Distribution {
+	case Uniform:
+		d = l.Min
+		if l.Max > l.Min {
+			d += time.Duration(i.rng.Int64N(int64(l.Max - l.Min)))
+		}
+	case Normal:
+		d = l.Mean + time.Duration(i.rng.NormFloat64()*float64(l.StdDev))
+	case Exponential:
+		d = l.Min + time.Duration(i.rng.ExpFloat64()*float64(l.Mean))
+	default:
+		d = l.Mean
+	}
+
+	if d < l.Min {
+		d = l.Min
+	}
+	if l.Max > 0 && d > l.Max {
+		d = l.Max
+	}
+	if d < 0 {
+		d = 0
+	}
+	return d
+}
+
+// UnaryServerInterceptor delays and fails calls according to Plan. Calls
+// whose context ends during the delay return its error, like a real
+// backend would. The gRPC infrastructure services (health, reflection)
+// are left alone.
+func (i *Injector) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if strings.HasPrefix(info.FullMethod, "/grpc.") {
+			return handler(ctx, req)
+		}
+
+		delay, err := i.Plan(methodName(info.FullMethod))
+		if delay > 0 {
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, status.FromContextError(ctx.Err()).Err()
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// methodName returns "GetUser" for "/user.UserService/GetUser".
+func methodName(fullMethod string) string {
+	if idx := strings.LastIndex(fullMethod, "/"); idx >= 0 {
+		return fullMethod[idx+1:]
+	}
+	return fullMethod
+}
@@ -0,0 +1,206 @@
+package tlsconfig
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+)
+
+type Settings struct {
+	// CAFile is a PEM bundle of the CAs that may sign backend
+	// certificates; empty means the system roots.
+	CAFile string
+	// CertFile and KeyFile hold the client certificate presented for
+	// mTLS. Both or neither must be set.
+	CertFile string
+	KeyFile  string
+	// ServerName overrides the name the backend certificate is verified
+	// against, which otherwise comes from the dialed address.
+	ServerName string
+	// MinVersion defaults to TLS 1.2, which is also the lowest allowed.
+	MinVersion uint16
+}
+
+// ParseVersion accepts "1.2" and "1.3"; empty means TLS 1.2. Older
+// versions are rejected as insecure.
+func ParseVersion(s string) (uint16, error) {
+	switch s {
+	case "":
+		return tls.VersionTLS12, nil
+	case "1.0", "1.1":
+		return 0, fmt.Errorf("TLS version %s is insecure, use 1.2 or 1.3", s)
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unknown TLS version %q", s)
+	}
+}
+
+// Reloader keeps the TLS configuration built from Settings and rebuilds it
+// when the files change on disk. Only handshakes made after a reload use
+// the new files; established connections keep theirs.
+type Reloader struct {
+	settings Settings
+
+	mu      sync.RWMutex
+	current *tls.Config
+	raw     []byte
+}
+
+// New loads the files once and fails if they do not make a valid
+// configuration.
+func New(settings Settings) (*Reloader, error) {
+	if (settings.CertFile == "") != (settings.KeyFile == "") {
+		return nil, errors.New("cert_file and key_file must be set together")
+	}
+	if settings.MinVersion < tls.VersionTLS12 {
+		settings.MinVersion = tls.VersionTLS12
+	}
+
+	r := &Reloader{settings: settings}
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Config returns a copy of the current configuration.
+func (r *Reloader) Config() *tls.Config {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.current.Clone()
+}
+
+// Reload reads the files again and swaps the configuration if their
+// content changed. An invalid set of files, e.g. a certificate rotated
+// before its key, keeps the previous configuration in place.
+func (r *Reloader) Reload() (bool, error) {
+	raw, files, err := r.read()
+	if err != nil {
+		return false, err
+	}
+
+	r.mu.RLock()
+	unchanged := r.current != nil && bytes.Equal(raw, r.raw)
+	r.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	config := &tls.Config{
+		ServerName: r.settings.ServerName,
+		MinVersion: r.settings.MinVersion,
+	}
+	if ca, ok := files[r.settings.CAFile]; ok {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return false, fmt.Errorf("no certificates found in %s", r.settings.CAFile)
+		}
+		config.RootCAs = pool
+	}
+	if r.settings.CertFile != "" {
+		cert, err := tls.X509KeyPair(files[r.settings.CertFile], files[r.settings.KeyFile])
+		if err != nil {
+			return false, fmt.Errorf("load client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	r.mu.Lock()
+	r.current, r.raw = config, raw
+	r.mu.Unlock()
+
+	return true, nil
+}
+
+func (r *Reloader) read() ([]byte, map[string][]byte, error) {
+	var raw []byte
+	files := make(map[string][]byte, 3)
+	for _, path := range []string{r.settings.CAFile, r.settings.CertFile, r.settings.KeyFile} {
+		if path == "" {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		files[path] = data
+		raw = append(raw, data...)
+	}
+	return raw, files, nil
+}
+
+// Watch calls Reload every interval until ctx is done.
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		reloaded, err := r.Reload()
+		switch {
+		case err != nil:
+			logger.WarnContext(ctx, "TLS reload failed, keeping previous certificates", "error", err)
+		case reloaded:
+			logger.InfoContext(ctx, "TLS certificates reloaded")
+		}
+	}
+}
+
+// Credentials returns gRPC client credentials that use the configuration
+// current at the time of each handshake.
+func (r *Reloader) Credentials() credentials.TransportCredentials {
+	return &reloadingCredentials{reloader: r}
+}
+
+type reloadingCredentials struct {
+	reloader   *Reloader
+	serverName string
+}
+
+func (c *reloadingCredentials) current() credentials.TransportCredentials {
+	config := c.reloader.Config()
+	if c.serverName != "" {
+		config.ServerName = c.serverName
+	}
+	return credentials.NewTLS(config)
+}
+
+func (c *reloadingCredentials) ClientHandshake(ctx context.Context, authority string, conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return c.current().ClientHandshake(ctx, authority, conn)
+}
+
+func (c *reloadingCredentials) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return nil, nil, errors.New("tlsconfig: credentials are client-only")
+}
+
+func (c *reloadingCredentials) Info() credentials.ProtocolInfo {
+	return c.current().Info()
+}
+
+func (c *reloadingCredentials) Clone() credentials.TransportCredentials {
+	clone := *c
+	return &clone
+}
+
+func (c *reloadingCredentials) OverrideServerName(serverName string) error {
+	c.serverName = serverName
+	return nil
+}
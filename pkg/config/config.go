@@ -51,14 +51,129 @@ func (c *ServiceConfig) GetGrpcTimeout() time.Duration {
 	return time.Duration(c.Grpc.TimeoutMs) * time.Millisecond
 }
 
-func (c *ServiceConfig) GetUserDegradationTimeout() time.Duration {
-	return time.Duration(c.Degradation.UserTimeoutMs) * time.Millisecond
+func (c *ServiceConfig) GetTLSReloadInterval() time.Duration {
+	return time.Duration(c.Grpc.TLS.ReloadIntervalMs) * time.Millisecond
 }
 
-func (c *ServiceConfig) GetVectorDegradationTimeout() time.Duration {
-	return time.Duration(c.Degradation.VectorTimeoutMs) * time.Millisecond
+func (c *ServiceConfig) GetEncodingReserve() time.Duration {
+	return time.Duration(c.Degradation.EncodingReserveMs) * time.Millisecond
 }
 
-func (c *ServiceConfig) GetPermissionsDegradationTimeout() time.Duration {
-	return time.Duration(c.Degradation.PermissionsTimeoutMs) * time.Millisecond
+func (c *ServiceConfig) GetBatchTimeout() time.Duration {
+	return time.Duration(c.Batch.TimeoutMs) * time.Millisecond
+}
+
+// GetLoadSheddingTarget defaults to the SLA.
+func (c *ServiceConfig) GetLoadSheddingTarget() time.Duration {
+	if c.LoadShedding.TargetMs <= 0 {
+		return c.GetSLATimeout()
+	}
+	return time.Duration(c.LoadShedding.TargetMs) * time.Millisecond
+}
+
+func (c *ServiceConfig) GetJWKSRefresh() time.Duration {
+	return time.Duration(c.Auth.JWKSRefreshMs) * time.Millisecond
+}
+
+func (c *ServiceConfig) GetAuthLeeway() time.Duration {
+	return time.Duration(c.Auth.LeewayMs) * time.Millisecond
+}
+
+func (c *ServiceConfig) GetHealthTimeout() time.Duration {
+	return time.Duration(c.Health.TimeoutMs) * time.Millisecond
+}
+
+func (c CircuitBreakerConfig) GetWindow() time.Duration {
+	return time.Duration(c.WindowMs) * time.Millisecond
+}
+
+func (c CircuitBreakerConfig) GetCoolDown() time.Duration {
+	return time.Duration(c.CoolDownMs) * time.Millisecond
+}
+
+func (c HedgingConfig) GetInitialDelay() time.Duration {
+	return time.Duration(c.InitialDelayMs) * time.Millisecond
+}
+
+func (c RetryConfig) GetInitialBackoff() time.Duration {
+	return time.Duration(c.InitialBackoffMs) * time.Millisecond
+}
+
+func (c RetryConfig) GetMaxBackoff() time.Duration {
+	return time.Duration(c.MaxBackoffMs) * time.Millisecond
+}
+
+func (c RetryConfig) GetMinAttemptTime() time.Duration {
+	return time.Duration(c.MinAttemptTimeMs) * time.Millisecond
+}
+
+func (c BudgetConfig) GetFloor() time.Duration {
+	return time.Duration(c.FloorMs) * time.Millisecond
+}
+
+func (c BudgetConfig) GetCeiling() time.Duration {
+	return time.Duration(c.CeilingMs) * time.Millisecond
+}
+
+func (c BudgetConfig) GetMinUseful() time.Duration {
+	return time.Duration(c.MinUsefulMs) * time.Millisecond
+}
+
+func (c CacheConfig) GetTTL() time.Duration {
+	return time.Duration(c.TTLMs) * time.Millisecond
+}
+
+func (c CacheConfig) GetStaleTTL() time.Duration {
+	return time.Duration(c.StaleTTLMs) * time.Millisecond
+}
+
+func (c CacheConfig) GetRefreshTimeout() time.Duration {
+	return time.Duration(c.RefreshTimeoutMs) * time.Millisecond
+}
+
+func (c MicroBatchingConfig) GetWindow() time.Duration {
+	return msToDuration(c.WindowMs)
+}
+
+func (c SimulatedMethodConfig) GetMean() time.Duration {
+	return msToDuration(c.MeanMs)
+}
+
+func (c SimulatedMethodConfig) GetStdDev() time.Duration {
+	return msToDuration(c.StdDevMs)
+}
+
+func (c SimulatedMethodConfig) GetMin() time.Duration {
+	return msToDuration(c.MinMs)
+}
+
+func (c SimulatedMethodConfig) GetMax() time.Duration {
+	return msToDuration(c.MaxMs)
+}
+
+func (c OutageConfig) GetStart() time.Duration {
+	return time.Duration(c.StartMs) * time.Millisecond
+}
+
+func (c OutageConfig) GetDuration() time.Duration {
+	return time.Duration(c.DurationMs) * time.Millisecond
+}
+
+func (c OutageConfig) GetEvery() time.Duration {
+	return time.Duration(c.EveryMs) * time.Millisecond
+}
+
+// msToDuration keeps sub-millisecond precision, which matters for
+// simulated latencies of fast backends.
+func msToDuration(ms float64) time.Duration {
+	return time.Duration(ms * float64(time.Millisecond))
+}
+
+// GetFaultsAdminAddr defaults to a loopback address, so that the admin
+// endpoint is only reachable from the gateway's host.
+func (c *ServiceConfig) GetFaultsAdminAddr() string {
+	if c.Faults.AdminAddr == "" {
+		return "127.0.0.1:9090"
+	}
+	return c.Faults.AdminAddr
 }
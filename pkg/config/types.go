@@ -5,6 +5,7 @@ type ServiceConfig struct {
 		Env         string `mapstructure:"env"`
 		Port        string `mapstructure:"port"`
 		LogLevel    string `mapstructure:"log_level"`
+		LogFormat   string `mapstructure:"log_format"`
 		ServiceName string `mapstructure:"service_name"`
 	} `mapstructure:"app"`
 	TTL struct {
@@ -16,10 +17,225 @@ type ServiceConfig struct {
 		VectorService      string `mapstructure:"vector_service"`
 		PermissionsService string `mapstructure:"permissions_service"`
 		TimeoutMs          int    `mapstructure:"timeout_ms"`
+		TLS                struct {
+			// ReloadIntervalMs is how often certificate files are checked
+			// for rotation.
+			ReloadIntervalMs int       `mapstructure:"reload_interval_ms"`
+			User             TLSConfig `mapstructure:"user"`
+			Vector           TLSConfig `mapstructure:"vector"`
+			Permissions      TLSConfig `mapstructure:"permissions"`
+		} `mapstructure:"tls"`
 	} `mapstructure:"grpc"`
 	Degradation struct {
-		UserTimeoutMs        int `mapstructure:"user_timeout_ms"`
-		VectorTimeoutMs      int `mapstructure:"vector_timeout_ms"`
-		PermissionsTimeoutMs int `mapstructure:"permissions_timeout_ms"`
+		VectorFallback string `mapstructure:"vector_fallback"`
+		// EncodingReserveMs of the SLA is kept back from the legs for
+		// writing the response.
+		EncodingReserveMs int          `mapstructure:"encoding_reserve_ms"`
+		User              BudgetConfig `mapstructure:"user"`
+		Vector            BudgetConfig `mapstructure:"vector"`
+		Permissions       BudgetConfig `mapstructure:"permissions"`
 	} `mapstructure:"degradation"`
+	// Auth puts JWT authentication in front of the summary endpoints. The
+	// user is then taken from the token's subject instead of user_id.
+	Auth struct {
+		Enabled bool `mapstructure:"enabled"`
+		// Algorithms defaults to HS256, RS256 and ES256.
+		Algorithms []string `mapstructure:"algorithms"`
+		// HMACSecretFile holds the HS256 shared secret.
+		HMACSecretFile string `mapstructure:"hmac_secret_file"`
+		JWKSFile       string `mapstructure:"jwks_file"`
+		JWKSURL        string `mapstructure:"jwks_url"`
+		JWKSRefreshMs  int    `mapstructure:"jwks_refresh_ms"`
+		Issuer         string `mapstructure:"issuer"`
+		Audience       string `mapstructure:"audience"`
+		LeewayMs       int    `mapstructure:"leeway_ms"`
+	} `mapstructure:"auth"`
+	// RateLimit limits each caller of the summary endpoints with a token
+	// bucket per client IP and, on top, per authenticated user.
+	RateLimit struct {
+		Enabled           bool            `mapstructure:"enabled"`
+		TrustForwardedFor bool            `mapstructure:"trust_forwarded_for"`
+		User              RateLimitConfig `mapstructure:"user"`
+		IP                RateLimitConfig `mapstructure:"ip"`
+	} `mapstructure:"rate_limit"`
+	// LoadShedding rejects requests with 503 once more are in flight than
+	// an adaptive limit, which shrinks while requests miss target_ms.
+	LoadShedding struct {
+		Enabled      bool    `mapstructure:"enabled"`
+		TargetMs     int     `mapstructure:"target_ms"`
+		InitialLimit int     `mapstructure:"initial_limit"`
+		MinLimit     int     `mapstructure:"min_limit"`
+		MaxLimit     int     `mapstructure:"max_limit"`
+		Backoff      float64 `mapstructure:"backoff"`
+	} `mapstructure:"load_shedding"`
+	Batch struct {
+		MaxItems    int `mapstructure:"max_items"`
+		Concurrency int `mapstructure:"concurrency"`
+		TimeoutMs   int `mapstructure:"timeout_ms"`
+	} `mapstructure:"batch"`
+	CircuitBreaker struct {
+		User        CircuitBreakerConfig `mapstructure:"user"`
+		Vector      CircuitBreakerConfig `mapstructure:"vector"`
+		Permissions CircuitBreakerConfig `mapstructure:"permissions"`
+	} `mapstructure:"circuit_breaker"`
+	Hedging struct {
+		User        HedgingConfig `mapstructure:"user"`
+		Vector      HedgingConfig `mapstructure:"vector"`
+		Permissions HedgingConfig `mapstructure:"permissions"`
+	} `mapstructure:"hedging"`
+	Retry struct {
+		BudgetRatio     float64     `mapstructure:"budget_ratio"`
+		BudgetMaxTokens int         `mapstructure:"budget_max_tokens"`
+		User            RetryConfig `mapstructure:"user"`
+		Vector          RetryConfig `mapstructure:"vector"`
+		Permissions     RetryConfig `mapstructure:"permissions"`
+	} `mapstructure:"retry"`
+	Cache struct {
+		User        CacheConfig `mapstructure:"user"`
+		Permissions CacheConfig `mapstructure:"permissions"`
+	} `mapstructure:"cache"`
+	Tracing struct {
+		Exporter    string  `mapstructure:"exporter"`
+		Endpoint    string  `mapstructure:"endpoint"`
+		Insecure    bool    `mapstructure:"insecure"`
+		SampleRatio float64 `mapstructure:"sample_ratio"`
+	} `mapstructure:"tracing"`
+	Health struct {
+		TimeoutMs       int  `mapstructure:"timeout_ms"`
+		GrpcHealthCheck bool `mapstructure:"grpc_health_check"`
+	} `mapstructure:"health"`
+	Faults struct {
+		// Enabled installs the fault-injection interceptor on the backend
+		// connections; it must stay off in production.
+		Enabled bool `mapstructure:"enabled"`
+		// Admin serves the rules at /admin/faults for changes at runtime,
+		// on AdminAddr rather than the public port.
+		Admin     bool              `mapstructure:"admin"`
+		AdminAddr string            `mapstructure:"admin_addr"`
+		Rules     []FaultRuleConfig `mapstructure:"rules"`
+	} `mapstructure:"faults"`
+	Coalescing struct {
+		User        bool `mapstructure:"user"`
+		Vector      bool `mapstructure:"vector"`
+		Permissions bool `mapstructure:"permissions"`
+	} `mapstructure:"coalescing"`
+	// MicroBatching merges concurrent single-item calls to a backend into
+	// its batch RPC; the backend must implement the Batch* methods.
+	MicroBatching struct {
+		User        MicroBatchingConfig `mapstructure:"user"`
+		Vector      MicroBatchingConfig `mapstructure:"vector"`
+		Permissions MicroBatchingConfig `mapstructure:"permissions"`
+	} `mapstructure:"micro_batching"`
+}
+
+// TLSConfig secures the connection to one backend. Without Enabled the
+// connection is plaintext.
+type TLSConfig struct {
+	Enabled    bool   `mapstructure:"enabled"`
+	CAFile     string `mapstructure:"ca_file"`
+	CertFile   string `mapstructure:"cert_file"`
+	KeyFile    string `mapstructure:"key_file"`
+	ServerName string `mapstructure:"server_name"`
+	// MinVersion is "1.2" or "1.3"; empty means 1.2.
+	MinVersion string `mapstructure:"min_version"`
+}
+
+// BudgetConfig gives a leg share of the time left in the request, at least
+// floor_ms and at most ceiling_ms. A leg with less than min_useful_ms left
+// is not started; zero always starts it.
+type BudgetConfig struct {
+	Share       float64 `mapstructure:"share"`
+	FloorMs     int     `mapstructure:"floor_ms"`
+	CeilingMs   int     `mapstructure:"ceiling_ms"`
+	MinUsefulMs int     `mapstructure:"min_useful_ms"`
+}
+
+// RateLimitConfig is a token bucket; zero requests_per_second disables it.
+type RateLimitConfig struct {
+	RequestsPerSecond float64 `mapstructure:"requests_per_second"`
+	Burst             int     `mapstructure:"burst"`
+}
+
+type CircuitBreakerConfig struct {
+	Enabled             bool    `mapstructure:"enabled"`
+	FailureRatio        float64 `mapstructure:"failure_ratio"`
+	MinRequests         int     `mapstructure:"min_requests"`
+	WindowMs            int     `mapstructure:"window_ms"`
+	CoolDownMs          int     `mapstructure:"cool_down_ms"`
+	HalfOpenMaxRequests int     `mapstructure:"half_open_max_requests"`
+}
+
+type HedgingConfig struct {
+	Enabled            bool    `mapstructure:"enabled"`
+	Percentile         float64 `mapstructure:"percentile"`
+	InitialDelayMs     int     `mapstructure:"initial_delay_ms"`
+	MinSamples         int     `mapstructure:"min_samples"`
+	SampleSize         int     `mapstructure:"sample_size"`
+	MaxHedgesPerSecond float64 `mapstructure:"max_hedges_per_second"`
+}
+
+type RetryConfig struct {
+	Enabled          bool     `mapstructure:"enabled"`
+	MaxAttempts      int      `mapstructure:"max_attempts"`
+	InitialBackoffMs int      `mapstructure:"initial_backoff_ms"`
+	MaxBackoffMs     int      `mapstructure:"max_backoff_ms"`
+	Multiplier       float64  `mapstructure:"multiplier"`
+	Jitter           float64  `mapstructure:"jitter"`
+	MinAttemptTimeMs int      `mapstructure:"min_attempt_time_ms"`
+	RetryableCodes   []string `mapstructure:"retryable_codes"`
+}
+
+type CacheConfig struct {
+	Enabled          bool `mapstructure:"enabled"`
+	TTLMs            int  `mapstructure:"ttl_ms"`
+	StaleTTLMs       int  `mapstructure:"stale_ttl_ms"`
+	MaxEntries       int  `mapstructure:"max_entries"`
+	RefreshTimeoutMs int  `mapstructure:"refresh_timeout_ms"`
+}
+
+type MicroBatchingConfig struct {
+	Enabled  bool    `mapstructure:"enabled"`
+	WindowMs float64 `mapstructure:"window_ms"`
+	MaxSize  int     `mapstructure:"max_size"`
+}
+
+type FaultRuleConfig struct {
+	Service    string  `mapstructure:"service"`
+	Method     string  `mapstructure:"method"`
+	Percentage float64 `mapstructure:"percentage"`
+	DelayMs    int     `mapstructure:"delay_ms"`
+	Code       string  `mapstructure:"code"`
+	Drop       bool    `mapstructure:"drop"`
+}
+
+// SimulatorConfig drives cmd/simulator, which serves the three backends
+// with injected latency and failures.
+type SimulatorConfig struct {
+	User        SimulatedServiceConfig `mapstructure:"user"`
+	Vector      SimulatedServiceConfig `mapstructure:"vector"`
+	Permissions SimulatedServiceConfig `mapstructure:"permissions"`
+}
+
+type SimulatedServiceConfig struct {
+	Addr string `mapstructure:"addr"`
+	// Methods is keyed by gRPC method name, e.g. "GetUser".
+	Methods map[string]SimulatedMethodConfig `mapstructure:"methods"`
+	Outages []OutageConfig                   `mapstructure:"outages"`
+}
+
+type SimulatedMethodConfig struct {
+	Distribution string  `mapstructure:"distribution"`
+	MeanMs       float64 `mapstructure:"mean_ms"`
+	StdDevMs     float64 `mapstructure:"stddev_ms"`
+	MinMs        float64 `mapstructure:"min_ms"`
+	MaxMs        float64 `mapstructure:"max_ms"`
+	ErrorRate    float64 `mapstructure:"error_rate"`
+	ErrorCode    string  `mapstructure:"error_code"`
+}
+
+type OutageConfig struct {
+	StartMs    int    `mapstructure:"start_ms"`
+	DurationMs int    `mapstructure:"duration_ms"`
+	EveryMs    int    `mapstructure:"every_ms"`
+	Code       string `mapstructure:"code"`
 }
@@ -7,6 +7,7 @@
 package permissions
 
 import (
+	status "google.golang.org/genproto/googleapis/rpc/status"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
@@ -141,11 +142,111 @@ func (x *CheckAccessResponse) GetReason() string {
 	return ""
 }
 
+type BatchCheckAccessRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Checks        []*CheckAccessRequest  `protobuf:"bytes,1,rep,name=checks,proto3" json:"checks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchCheckAccessRequest) Reset() {
+	*x = BatchCheckAccessRequest{}
+	mi := &file_permissions_permissions_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchCheckAccessRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchCheckAccessRequest) ProtoMessage() {}
+
+func (x *BatchCheckAccessRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_permissions_permissions_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchCheckAccessRequest.ProtoReflect.Descriptor instead.
+func (*BatchCheckAccessRequest) Descriptor() ([]byte, []int) {
+	return file_permissions_permissions_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *BatchCheckAccessRequest) GetChecks() []*CheckAccessRequest {
+	if x != nil {
+		return x.Checks
+	}
+	return nil
+}
+
+// results holds one entry per check, in request order.
+type BatchCheckAccessResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Results []*CheckAccessResponse `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	// statuses holds the outcome of each entry of results, in the same order.
+	// It may be empty when every entry succeeded. Entries whose status is not
+	// OK have no result; the batch itself fails only when the call does.
+	Statuses      []*status.Status `protobuf:"bytes,2,rep,name=statuses,proto3" json:"statuses,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchCheckAccessResponse) Reset() {
+	*x = BatchCheckAccessResponse{}
+	mi := &file_permissions_permissions_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchCheckAccessResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchCheckAccessResponse) ProtoMessage() {}
+
+func (x *BatchCheckAccessResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_permissions_permissions_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchCheckAccessResponse.ProtoReflect.Descriptor instead.
+func (*BatchCheckAccessResponse) Descriptor() ([]byte, []int) {
+	return file_permissions_permissions_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *BatchCheckAccessResponse) GetResults() []*CheckAccessResponse {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *BatchCheckAccessResponse) GetStatuses() []*status.Status {
+	if x != nil {
+		return x.Statuses
+	}
+	return nil
+}
+
 var File_permissions_permissions_proto protoreflect.FileDescriptor
 
 const file_permissions_permissions_proto_rawDesc = "" +
 	"\n" +
-	"\x1dpermissions/permissions.proto\x12\vpermissions\"f\n" +
+	"\x1dpermissions/permissions.proto\x12\vpermissions\x1a\x17google/rpc/status.proto\"f\n" +
 	"\x12CheckAccessRequest\x12\x17\n" +
 	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1f\n" +
 	"\vresource_id\x18\x02 \x01(\tR\n" +
@@ -154,9 +255,15 @@ const file_permissions_permissions_proto_rawDesc = "" +
 	"\x13CheckAccessResponse\x12\x18\n" +
 	"\aallowed\x18\x01 \x01(\bR\aallowed\x12 \n" +
 	"\vpermissions\x18\x02 \x03(\tR\vpermissions\x12\x16\n" +
-	"\x06reason\x18\x03 \x01(\tR\x06reason2f\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\"R\n" +
+	"\x17BatchCheckAccessRequest\x127\n" +
+	"\x06checks\x18\x01 \x03(\v2\x1f.permissions.CheckAccessRequestR\x06checks\"\x86\x01\n" +
+	"\x18BatchCheckAccessResponse\x12:\n" +
+	"\aresults\x18\x01 \x03(\v2 .permissions.CheckAccessResponseR\aresults\x12.\n" +
+	"\bstatuses\x18\x02 \x03(\v2\x12.google.rpc.StatusR\bstatuses2\xc7\x01\n" +
 	"\x12PermissionsService\x12P\n" +
-	"\vCheckAccess\x12\x1f.permissions.CheckAccessRequest\x1a .permissions.CheckAccessResponseB>Z<github.com/vwency/resilient-scatter-gather/proto/permissionsb\x06proto3"
+	"\vCheckAccess\x12\x1f.permissions.CheckAccessRequest\x1a .permissions.CheckAccessResponse\x12_\n" +
+	"\x10BatchCheckAccess\x12$.permissions.BatchCheckAccessRequest\x1a%.permissions.BatchCheckAccessResponseB>Z<github.com/vwency/resilient-scatter-gather/proto/permissionsb\x06proto3"
 
 var (
 	file_permissions_permissions_proto_rawDescOnce sync.Once
@@ -170,19 +277,27 @@ func file_permissions_permissions_proto_rawDescGZIP() []byte {
 	return file_permissions_permissions_proto_rawDescData
 }
 
-var file_permissions_permissions_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_permissions_permissions_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
 var file_permissions_permissions_proto_goTypes = []any{
-	(*CheckAccessRequest)(nil),  // 0: permissions.CheckAccessRequest
-	(*CheckAccessResponse)(nil), // 1: permissions.CheckAccessResponse
+	(*CheckAccessRequest)(nil),       // 0: permissions.CheckAccessRequest
+	(*CheckAccessResponse)(nil),      // 1: permissions.CheckAccessResponse
+	(*BatchCheckAccessRequest)(nil),  // 2: permissions.BatchCheckAccessRequest
+	(*BatchCheckAccessResponse)(nil), // 3: permissions.BatchCheckAccessResponse
+	(*status.Status)(nil),            // 4: google.rpc.Status
 }
 var file_permissions_permissions_proto_depIdxs = []int32{
-	0, // 0: permissions.PermissionsService.CheckAccess:input_type -> permissions.CheckAccessRequest
-	1, // 1: permissions.PermissionsService.CheckAccess:output_type -> permissions.CheckAccessResponse
-	1, // [1:2] is the sub-list for method output_type
-	0, // [0:1] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	0, // 0: permissions.BatchCheckAccessRequest.checks:type_name -> permissions.CheckAccessRequest
+	1, // 1: permissions.BatchCheckAccessResponse.results:type_name -> permissions.CheckAccessResponse
+	4, // 2: permissions.BatchCheckAccessResponse.statuses:type_name -> google.rpc.Status
+	0, // 3: permissions.PermissionsService.CheckAccess:input_type -> permissions.CheckAccessRequest
+	2, // 4: permissions.PermissionsService.BatchCheckAccess:input_type -> permissions.BatchCheckAccessRequest
+	1, // 5: permissions.PermissionsService.CheckAccess:output_type -> permissions.CheckAccessResponse
+	3, // 6: permissions.PermissionsService.BatchCheckAccess:output_type -> permissions.BatchCheckAccessResponse
+	5, // [5:7] is the sub-list for method output_type
+	3, // [3:5] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
 }
 
 func init() { file_permissions_permissions_proto_init() }
@@ -196,7 +311,7 @@ func file_permissions_permissions_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_permissions_permissions_proto_rawDesc), len(file_permissions_permissions_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   2,
+			NumMessages:   4,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
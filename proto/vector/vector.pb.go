@@ -7,6 +7,7 @@
 package vector
 
 import (
+	status "google.golang.org/genproto/googleapis/rpc/status"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
@@ -193,11 +194,112 @@ func (x *ContextItem) GetTimestamp() int64 {
 	return 0
 }
 
+type BatchGetContextRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Requests      []*GetContextRequest   `protobuf:"bytes,1,rep,name=requests,proto3" json:"requests,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetContextRequest) Reset() {
+	*x = BatchGetContextRequest{}
+	mi := &file_vector_vector_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetContextRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetContextRequest) ProtoMessage() {}
+
+func (x *BatchGetContextRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_vector_vector_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetContextRequest.ProtoReflect.Descriptor instead.
+func (*BatchGetContextRequest) Descriptor() ([]byte, []int) {
+	return file_vector_vector_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *BatchGetContextRequest) GetRequests() []*GetContextRequest {
+	if x != nil {
+		return x.Requests
+	}
+	return nil
+}
+
+// contexts holds one entry per request, in request order.
+type BatchGetContextResponse struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Contexts []*GetContextResponse  `protobuf:"bytes,1,rep,name=contexts,proto3" json:"contexts,omitempty"`
+	// statuses holds the outcome of each entry of contexts, in the same
+	// order. It may be empty when every entry succeeded. Entries whose status
+	// is not OK have no context; the batch itself fails only when the call
+	// does.
+	Statuses      []*status.Status `protobuf:"bytes,2,rep,name=statuses,proto3" json:"statuses,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetContextResponse) Reset() {
+	*x = BatchGetContextResponse{}
+	mi := &file_vector_vector_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetContextResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetContextResponse) ProtoMessage() {}
+
+func (x *BatchGetContextResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_vector_vector_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetContextResponse.ProtoReflect.Descriptor instead.
+func (*BatchGetContextResponse) Descriptor() ([]byte, []int) {
+	return file_vector_vector_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *BatchGetContextResponse) GetContexts() []*GetContextResponse {
+	if x != nil {
+		return x.Contexts
+	}
+	return nil
+}
+
+func (x *BatchGetContextResponse) GetStatuses() []*status.Status {
+	if x != nil {
+		return x.Statuses
+	}
+	return nil
+}
+
 var File_vector_vector_proto protoreflect.FileDescriptor
 
 const file_vector_vector_proto_rawDesc = "" +
 	"\n" +
-	"\x13vector/vector.proto\x12\x06vector\"B\n" +
+	"\x13vector/vector.proto\x12\x06vector\x1a\x17google/rpc/status.proto\"B\n" +
 	"\x11GetContextRequest\x12\x17\n" +
 	"\achat_id\x18\x01 \x01(\tR\x06chatId\x12\x14\n" +
 	"\x05limit\x18\x02 \x01(\x05R\x05limit\"`\n" +
@@ -210,10 +312,16 @@ const file_vector_vector_proto_rawDesc = "" +
 	"message_id\x18\x01 \x01(\tR\tmessageId\x12\x18\n" +
 	"\acontent\x18\x02 \x01(\tR\acontent\x12'\n" +
 	"\x0frelevance_score\x18\x03 \x01(\x01R\x0erelevanceScore\x12\x1c\n" +
-	"\ttimestamp\x18\x04 \x01(\x03R\ttimestamp2Z\n" +
+	"\ttimestamp\x18\x04 \x01(\x03R\ttimestamp\"O\n" +
+	"\x16BatchGetContextRequest\x125\n" +
+	"\brequests\x18\x01 \x03(\v2\x19.vector.GetContextRequestR\brequests\"\x81\x01\n" +
+	"\x17BatchGetContextResponse\x126\n" +
+	"\bcontexts\x18\x01 \x03(\v2\x1a.vector.GetContextResponseR\bcontexts\x12.\n" +
+	"\bstatuses\x18\x02 \x03(\v2\x12.google.rpc.StatusR\bstatuses2\xae\x01\n" +
 	"\x13VectorMemoryService\x12C\n" +
 	"\n" +
-	"GetContext\x12\x19.vector.GetContextRequest\x1a\x1a.vector.GetContextResponseB9Z7github.com/vwency/resilient-scatter-gather/proto/vectorb\x06proto3"
+	"GetContext\x12\x19.vector.GetContextRequest\x1a\x1a.vector.GetContextResponse\x12R\n" +
+	"\x0fBatchGetContext\x12\x1e.vector.BatchGetContextRequest\x1a\x1f.vector.BatchGetContextResponseB9Z7github.com/vwency/resilient-scatter-gather/proto/vectorb\x06proto3"
 
 var (
 	file_vector_vector_proto_rawDescOnce sync.Once
@@ -227,21 +335,29 @@ func file_vector_vector_proto_rawDescGZIP() []byte {
 	return file_vector_vector_proto_rawDescData
 }
 
-var file_vector_vector_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_vector_vector_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
 var file_vector_vector_proto_goTypes = []any{
-	(*GetContextRequest)(nil),  // 0: vector.GetContextRequest
-	(*GetContextResponse)(nil), // 1: vector.GetContextResponse
-	(*ContextItem)(nil),        // 2: vector.ContextItem
+	(*GetContextRequest)(nil),       // 0: vector.GetContextRequest
+	(*GetContextResponse)(nil),      // 1: vector.GetContextResponse
+	(*ContextItem)(nil),             // 2: vector.ContextItem
+	(*BatchGetContextRequest)(nil),  // 3: vector.BatchGetContextRequest
+	(*BatchGetContextResponse)(nil), // 4: vector.BatchGetContextResponse
+	(*status.Status)(nil),           // 5: google.rpc.Status
 }
 var file_vector_vector_proto_depIdxs = []int32{
 	2, // 0: vector.GetContextResponse.items:type_name -> vector.ContextItem
-	0, // 1: vector.VectorMemoryService.GetContext:input_type -> vector.GetContextRequest
-	1, // 2: vector.VectorMemoryService.GetContext:output_type -> vector.GetContextResponse
-	2, // [2:3] is the sub-list for method output_type
-	1, // [1:2] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	0, // 1: vector.BatchGetContextRequest.requests:type_name -> vector.GetContextRequest
+	1, // 2: vector.BatchGetContextResponse.contexts:type_name -> vector.GetContextResponse
+	5, // 3: vector.BatchGetContextResponse.statuses:type_name -> google.rpc.Status
+	0, // 4: vector.VectorMemoryService.GetContext:input_type -> vector.GetContextRequest
+	3, // 5: vector.VectorMemoryService.BatchGetContext:input_type -> vector.BatchGetContextRequest
+	1, // 6: vector.VectorMemoryService.GetContext:output_type -> vector.GetContextResponse
+	4, // 7: vector.VectorMemoryService.BatchGetContext:output_type -> vector.BatchGetContextResponse
+	6, // [6:8] is the sub-list for method output_type
+	4, // [4:6] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
 }
 
 func init() { file_vector_vector_proto_init() }
@@ -255,7 +371,7 @@ func file_vector_vector_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_vector_vector_proto_rawDesc), len(file_vector_vector_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   3,
+			NumMessages:   5,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
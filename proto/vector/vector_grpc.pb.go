@@ -19,7 +19,8 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	VectorMemoryService_GetContext_FullMethodName = "/vector.VectorMemoryService/GetContext"
+	VectorMemoryService_GetContext_FullMethodName      = "/vector.VectorMemoryService/GetContext"
+	VectorMemoryService_BatchGetContext_FullMethodName = "/vector.VectorMemoryService/BatchGetContext"
 )
 
 // VectorMemoryServiceClient is the client API for VectorMemoryService service.
@@ -27,6 +28,7 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type VectorMemoryServiceClient interface {
 	GetContext(ctx context.Context, in *GetContextRequest, opts ...grpc.CallOption) (*GetContextResponse, error)
+	BatchGetContext(ctx context.Context, in *BatchGetContextRequest, opts ...grpc.CallOption) (*BatchGetContextResponse, error)
 }
 
 type vectorMemoryServiceClient struct {
@@ -47,11 +49,22 @@ func (c *vectorMemoryServiceClient) GetContext(ctx context.Context, in *GetConte
 	return out, nil
 }
 
+func (c *vectorMemoryServiceClient) BatchGetContext(ctx context.Context, in *BatchGetContextRequest, opts ...grpc.CallOption) (*BatchGetContextResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchGetContextResponse)
+	err := c.cc.Invoke(ctx, VectorMemoryService_BatchGetContext_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // VectorMemoryServiceServer is the server API for VectorMemoryService service.
 // All implementations must embed UnimplementedVectorMemoryServiceServer
 // for forward compatibility.
 type VectorMemoryServiceServer interface {
 	GetContext(context.Context, *GetContextRequest) (*GetContextResponse, error)
+	BatchGetContext(context.Context, *BatchGetContextRequest) (*BatchGetContextResponse, error)
 	mustEmbedUnimplementedVectorMemoryServiceServer()
 }
 
@@ -65,6 +78,9 @@ type UnimplementedVectorMemoryServiceServer struct{}
 func (UnimplementedVectorMemoryServiceServer) GetContext(context.Context, *GetContextRequest) (*GetContextResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method GetContext not implemented")
 }
+func (UnimplementedVectorMemoryServiceServer) BatchGetContext(context.Context, *BatchGetContextRequest) (*BatchGetContextResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method BatchGetContext not implemented")
+}
 func (UnimplementedVectorMemoryServiceServer) mustEmbedUnimplementedVectorMemoryServiceServer() {}
 func (UnimplementedVectorMemoryServiceServer) testEmbeddedByValue()                             {}
 
@@ -104,6 +120,24 @@ func _VectorMemoryService_GetContext_Handler(srv interface{}, ctx context.Contex
 	return interceptor(ctx, in, info, handler)
 }
 
+func _VectorMemoryService_BatchGetContext_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchGetContextRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VectorMemoryServiceServer).BatchGetContext(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VectorMemoryService_BatchGetContext_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VectorMemoryServiceServer).BatchGetContext(ctx, req.(*BatchGetContextRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // VectorMemoryService_ServiceDesc is the grpc.ServiceDesc for VectorMemoryService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -115,6 +149,10 @@ var VectorMemoryService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetContext",
 			Handler:    _VectorMemoryService_GetContext_Handler,
 		},
+		{
+			MethodName: "BatchGetContext",
+			Handler:    _VectorMemoryService_BatchGetContext_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "vector/vector.proto",
@@ -0,0 +1,374 @@
+package auth_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vwency/resilient-scatter-gather/internal/auth"
+)
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func rsaJWK(kid string, pub *rsa.PublicKey) map[string]string {
+	return map[string]string{
+		"kty": "RSA", "kid": kid, "use": "sig",
+		"n": b64(pub.N.Bytes()),
+		"e": b64(big.NewInt(int64(pub.E)).Bytes()),
+	}
+}
+
+func ecJWK(kid string, pub *ecdsa.PublicKey) map[string]string {
+	return map[string]string{
+		"kty": "EC", "kid": kid, "crv": "P-256",
+		"x": b64(pub.X.FillBytes(make([]byte, 32))),
+		"y": b64(pub.Y.FillBytes(make([]byte, 32))),
+	}
+}
+
+func jwks(t *testing.T, keys ...map[string]string) []byte {
+	t.Helper()
+
+	data, err := json.Marshal(map[string]any{"keys": keys})
+	require.NoError(t, err)
+	return data
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key any, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func validClaims() jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"sub": "alice",
+		"iss": "https://issuer.example",
+		"aud": "gateway",
+		"iat": now.Unix(),
+		"exp": now.Add(time.Minute).Unix(),
+	}
+}
+
+var strict = auth.Settings{Issuer: "https://issuer.example", Audience: "gateway"}
+
+func TestVerifier_AcceptsEverySupportedAlgorithm(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	secret := []byte("0123456789abcdef0123456789abcdef")
+
+	set, err := auth.ParseKeySet(jwks(t, rsaJWK("rsa-1", &rsaKey.PublicKey), ecJWK("ec-1", &ecKey.PublicKey)))
+	require.NoError(t, err)
+	verifier, err := auth.NewVerifier(strict, auth.Keys{set, auth.SecretKey(secret)})
+	require.NoError(t, err)
+
+	tokens := map[string]string{
+		"RS256": sign(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, validClaims()),
+		"ES256": sign(t, jwt.SigningMethodES256, "ec-1", ecKey, validClaims()),
+		"HS256": sign(t, jwt.SigningMethodHS256, "", secret, validClaims()),
+	}
+	for alg, token := range tokens {
+		t.Run(alg, func(t *testing.T) {
+			identity, err := verifier.Verify(context.Background(), token)
+			require.NoError(t, err)
+			assert.Equal(t, "alice", identity.Subject)
+		})
+	}
+}
+
+func TestVerifier_RejectsInvalidTokens(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	verifier, err := auth.NewVerifier(strict, auth.SecretKey(secret))
+	require.NoError(t, err)
+
+	with := func(key string, value any) jwt.MapClaims {
+		claims := validClaims()
+		if value == nil {
+			delete(claims, key)
+		} else {
+			claims[key] = value
+		}
+		return claims
+	}
+
+	tests := map[string]string{
+		"expired":         sign(t, jwt.SigningMethodHS256, "", secret, with("exp", time.Now().Add(-time.Minute).Unix())),
+		"no expiry":       sign(t, jwt.SigningMethodHS256, "", secret, with("exp", nil)),
+		"wrong issuer":    sign(t, jwt.SigningMethodHS256, "", secret, with("iss", "https://evil.example")),
+		"wrong audience":  sign(t, jwt.SigningMethodHS256, "", secret, with("aud", "other")),
+		"no subject":      sign(t, jwt.SigningMethodHS256, "", secret, with("sub", nil)),
+		"not yet valid":   sign(t, jwt.SigningMethodHS256, "", secret, with("nbf", time.Now().Add(time.Hour).Unix())),
+		"wrong secret":    sign(t, jwt.SigningMethodHS256, "", []byte("another secret of enough length!"), validClaims()),
+		"unsigned (none)": sign(t, jwt.SigningMethodNone, "", jwt.UnsafeAllowNoneSignatureType, validClaims()),
+		"garbage":         "not.a.token",
+	}
+	for name, token := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := verifier.Verify(context.Background(), token)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestVerifier_AlgorithmsRestrictAcceptedTokens(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	verifier, err := auth.NewVerifier(auth.Settings{Algorithms: []string{"RS256"}}, auth.SecretKey(secret))
+	require.NoError(t, err)
+
+	_, err = verifier.Verify(context.Background(), sign(t, jwt.SigningMethodHS256, "", secret, validClaims()))
+
+	assert.Error(t, err)
+}
+
+func TestNewVerifier_RejectsUnsupportedAlgorithm(t *testing.T) {
+	_, err := auth.NewVerifier(auth.Settings{Algorithms: []string{"PS512"}}, auth.Keys{})
+
+	assert.ErrorContains(t, err, "unsupported algorithm")
+}
+
+func TestLoadKeySetFile_SelectsKeyByKid(t *testing.T) {
+	oldKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	newKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, jwks(t, ecJWK("old", &oldKey.PublicKey), ecJWK("new", &newKey.PublicKey)), 0o600))
+	set, err := auth.LoadKeySetFile(path)
+	require.NoError(t, err)
+	verifier, err := auth.NewVerifier(auth.Settings{}, set)
+	require.NoError(t, err)
+
+	_, err = verifier.Verify(context.Background(), sign(t, jwt.SigningMethodES256, "new", newKey, validClaims()))
+	assert.NoError(t, err)
+
+	_, err = verifier.Verify(context.Background(), sign(t, jwt.SigningMethodES256, "old", newKey, validClaims()))
+	assert.Error(t, err, "a token signed by another key than its kid names must fail")
+
+	_, err = verifier.Verify(context.Background(), sign(t, jwt.SigningMethodES256, "", newKey, validClaims()))
+	assert.ErrorIs(t, err, auth.ErrKeyNotFound, "without a kid the key is ambiguous")
+}
+
+func TestRemoteKeySet_UnknownKidRefetchesRotatedKeys(t *testing.T) {
+	first, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	second, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var document atomic.Value
+	document.Store(jwks(t, rsaJWK("k1", &first.PublicKey)))
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		_, _ = w.Write(document.Load().([]byte))
+	}))
+	defer server.Close()
+
+	keys := auth.NewRemoteKeySet(auth.RemoteSettings{URL: server.URL, Refresh: time.Hour, MinRefetch: time.Millisecond})
+	verifier, err := auth.NewVerifier(auth.Settings{}, keys)
+	require.NoError(t, err)
+
+	_, err = verifier.Verify(context.Background(), sign(t, jwt.SigningMethodRS256, "k1", first, validClaims()))
+	require.NoError(t, err)
+	_, err = verifier.Verify(context.Background(), sign(t, jwt.SigningMethodRS256, "k1", first, validClaims()))
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), fetches.Load(), "a cached set must be reused")
+
+	document.Store(jwks(t, rsaJWK("k1", &first.PublicKey), rsaJWK("k2", &second.PublicKey)))
+	time.Sleep(5 * time.Millisecond)
+
+	// The unknown kid fails while the rotated set is fetched in the
+	// background, and is found once it arrives.
+	rotated := sign(t, jwt.SigningMethodRS256, "k2", second, validClaims())
+	_, err = verifier.Verify(context.Background(), rotated)
+	assert.Error(t, err)
+	require.Eventually(t, func() bool {
+		_, err := verifier.Verify(context.Background(), rotated)
+		return err == nil
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, int32(2), fetches.Load())
+}
+
+func TestRemoteKeySet_FailedFetchKeepsPreviousSet(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var down atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down.Load() {
+			http.Error(w, "down", http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write(jwks(t, rsaJWK("k1", &key.PublicKey)))
+	}))
+	defer server.Close()
+
+	keys := auth.NewRemoteKeySet(auth.RemoteSettings{URL: server.URL, Refresh: time.Millisecond, MinRefetch: time.Millisecond})
+	verifier, err := auth.NewVerifier(auth.Settings{}, keys)
+	require.NoError(t, err)
+	token := sign(t, jwt.SigningMethodRS256, "k1", key, validClaims())
+
+	_, err = verifier.Verify(context.Background(), token)
+	require.NoError(t, err)
+
+	down.Store(true)
+	time.Sleep(5 * time.Millisecond)
+
+	_, err = verifier.Verify(context.Background(), token)
+	assert.NoError(t, err)
+}
+
+func TestRemoteKeySet_SlowFetchDoesNotBlockCachedKeys(t *testing.T) {
+	first, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	stuck := make(chan struct{})
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fetches.Add(1) > 1 {
+			<-stuck
+		}
+		_, _ = w.Write(jwks(t, rsaJWK("k1", &first.PublicKey)))
+	}))
+	defer server.Close()
+	defer close(stuck)
+
+	keys := auth.NewRemoteKeySet(auth.RemoteSettings{URL: server.URL, Refresh: time.Hour, MinRefetch: time.Millisecond})
+	verifier, err := auth.NewVerifier(auth.Settings{}, keys)
+	require.NoError(t, err)
+	known := sign(t, jwt.SigningMethodRS256, "k1", first, validClaims())
+
+	_, err = verifier.Verify(context.Background(), known)
+	require.NoError(t, err)
+	time.Sleep(5 * time.Millisecond)
+
+	// Unknown kids start one background refetch, which hangs.
+	for range 3 {
+		_, err = verifier.Verify(context.Background(), sign(t, jwt.SigningMethodRS256, "k2", first, validClaims()))
+		assert.Error(t, err)
+	}
+	require.Eventually(t, func() bool { return fetches.Load() == 2 }, time.Second, time.Millisecond)
+
+	start := time.Now()
+	_, err = verifier.Verify(context.Background(), known)
+	assert.NoError(t, err)
+	assert.Less(t, time.Since(start), 100*time.Millisecond, "cached keys are served during a fetch")
+	assert.Equal(t, int32(2), fetches.Load())
+}
+
+func TestRemoteKeySet_StaleSetIsServedWhileRefreshing(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	stuck := make(chan struct{})
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fetches.Add(1) > 1 {
+			<-stuck
+		}
+		_, _ = w.Write(jwks(t, rsaJWK("k1", &key.PublicKey)))
+	}))
+	defer server.Close()
+	defer close(stuck)
+
+	keys := auth.NewRemoteKeySet(auth.RemoteSettings{URL: server.URL, Refresh: time.Millisecond, MinRefetch: time.Millisecond})
+	verifier, err := auth.NewVerifier(auth.Settings{}, keys)
+	require.NoError(t, err)
+	token := sign(t, jwt.SigningMethodRS256, "k1", key, validClaims())
+
+	_, err = verifier.Verify(context.Background(), token)
+	require.NoError(t, err)
+	time.Sleep(5 * time.Millisecond)
+
+	start := time.Now()
+	for range 3 {
+		_, err = verifier.Verify(context.Background(), token)
+		assert.NoError(t, err)
+	}
+	assert.Less(t, time.Since(start), 100*time.Millisecond, "a stale set is served while it is refreshed")
+	require.Eventually(t, func() bool { return fetches.Load() == 2 }, time.Second, time.Millisecond)
+}
+
+func TestRemoteKeySet_RejectsSymmetricKeys(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(jwks(t, map[string]string{"kty": "oct", "kid": "k1", "k": b64(secret)}))
+	}))
+	defer server.Close()
+
+	verifier, err := auth.NewVerifier(auth.Settings{}, auth.NewRemoteKeySet(auth.RemoteSettings{URL: server.URL}))
+	require.NoError(t, err)
+
+	_, err = verifier.Verify(context.Background(), sign(t, jwt.SigningMethodHS256, "k1", secret, validClaims()))
+	assert.Error(t, err)
+}
+
+func TestMiddleware(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	verifier, err := auth.NewVerifier(auth.Settings{}, auth.SecretKey(secret))
+	require.NoError(t, err)
+
+	var subject string
+	h := auth.Middleware(verifier, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity, _ := auth.FromContext(r.Context())
+		subject = identity.Subject
+	}))
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantCode      int
+		wantChallenge string
+	}{
+		{name: "valid", authorization: "Bearer " + sign(t, jwt.SigningMethodHS256, "", secret, validClaims()), wantCode: http.StatusOK},
+		{name: "missing", authorization: "", wantCode: http.StatusUnauthorized, wantChallenge: "Bearer"},
+		{name: "other scheme", authorization: "Basic YWxpY2U6cHc=", wantCode: http.StatusUnauthorized, wantChallenge: "Bearer"},
+		{name: "invalid", authorization: "Bearer not.a.token", wantCode: http.StatusUnauthorized, wantChallenge: `Bearer error="invalid_token"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subject = ""
+			req := httptest.NewRequest("GET", "/api/v1/chat/summary?chat_id=c1", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			assert.Equal(t, tt.wantChallenge, w.Header().Get("WWW-Authenticate"))
+			if tt.wantCode == http.StatusOK {
+				assert.Equal(t, "alice", subject)
+			} else {
+				assert.Empty(t, subject)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package backendstub_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vwency/resilient-scatter-gather/internal/backendstub"
+	"github.com/vwency/resilient-scatter-gather/internal/services"
+	pb_user "github.com/vwency/resilient-scatter-gather/proto/user"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+)
+
+func startUserStub(t *testing.T) (*backendstub.Server, *grpc.ClientConn) {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := backendstub.NewServer(&pb_user.UserService_ServiceDesc, services.NewUserServiceServer())
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return server, conn
+}
+
+func checkHealth(t *testing.T, conn *grpc.ClientConn, service string) healthpb.HealthCheckResponse_ServingStatus {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+	require.NoError(t, err)
+	return resp.GetStatus()
+}
+
+func TestNewServer_ServesBackendAndHealth(t *testing.T) {
+	_, conn := startUserStub(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	resp, err := pb_user.NewUserServiceClient(conn).GetUser(ctx, &pb_user.GetUserRequest{UserId: "user123"})
+	require.NoError(t, err)
+	assert.Equal(t, "user123", resp.GetUserId())
+
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, checkHealth(t, conn, ""))
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, checkHealth(t, conn, "user.UserService"))
+}
+
+func TestSetServing_TogglesHealthStatus(t *testing.T) {
+	server, conn := startUserStub(t)
+
+	server.SetServing(false)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, checkHealth(t, conn, "user.UserService"))
+
+	server.SetServing(true)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, checkHealth(t, conn, "user.UserService"))
+}
+
+func TestNewServer_RegistersReflection(t *testing.T) {
+	_, conn := startUserStub(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	}))
+
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+
+	var names []string
+	for _, svc := range resp.GetListServicesResponse().GetService() {
+		names = append(names, svc.GetName())
+	}
+	assert.Contains(t, names, "user.UserService")
+	assert.Contains(t, names, "grpc.health.v1.Health")
+}
@@ -0,0 +1,154 @@
+package breaker_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vwency/resilient-scatter-gather/internal/breaker"
+)
+
+func newTestBreaker() *breaker.CircuitBreaker {
+	return breaker.New("test", breaker.Settings{
+		FailureRatio:        0.5,
+		MinRequests:         4,
+		Window:              time.Second,
+		CoolDown:            30 * time.Millisecond,
+		HalfOpenMaxRequests: 2,
+	})
+}
+
+func call(cb *breaker.CircuitBreaker, failure bool) error {
+	generation, err := cb.Allow()
+	if err != nil {
+		return err
+	}
+	cb.Record(generation, failure)
+	return nil
+}
+
+func allow(t *testing.T, cb *breaker.CircuitBreaker) uint64 {
+	t.Helper()
+
+	generation, err := cb.Allow()
+	require.NoError(t, err)
+	return generation
+}
+
+func TestCircuitBreaker_BelowMinRequests_StaysClosed(t *testing.T) {
+	cb := newTestBreaker()
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, call(cb, true))
+	}
+
+	assert.Equal(t, breaker.Closed, cb.State())
+}
+
+func TestCircuitBreaker_FailureRatioReached_Opens(t *testing.T) {
+	cb := newTestBreaker()
+
+	assert.NoError(t, call(cb, false))
+	assert.NoError(t, call(cb, false))
+	assert.NoError(t, call(cb, true))
+	assert.NoError(t, call(cb, true))
+
+	assert.Equal(t, breaker.Open, cb.State())
+
+	_, err := cb.Allow()
+	assert.True(t, errors.Is(err, breaker.ErrOpen))
+}
+
+func TestCircuitBreaker_WindowExpires_ResetsCounts(t *testing.T) {
+	cb := breaker.New("test", breaker.Settings{
+		FailureRatio: 0.5,
+		MinRequests:  4,
+		Window:       20 * time.Millisecond,
+		CoolDown:     time.Second,
+	})
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, call(cb, true))
+	}
+	time.Sleep(30 * time.Millisecond)
+	assert.NoError(t, call(cb, true))
+
+	assert.Equal(t, breaker.Closed, cb.State())
+}
+
+func TestCircuitBreaker_CoolDownElapsed_ProbesCloseBreaker(t *testing.T) {
+	cb := newTestBreaker()
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, call(cb, true))
+	}
+	assert.Equal(t, breaker.Open, cb.State())
+
+	time.Sleep(40 * time.Millisecond)
+	assert.Equal(t, breaker.HalfOpen, cb.State())
+
+	first := allow(t, cb)
+	second := allow(t, cb)
+	_, err := cb.Allow()
+	assert.ErrorIs(t, err, breaker.ErrOpen)
+
+	cb.Record(first, false)
+	cb.Record(second, false)
+
+	assert.Equal(t, breaker.Closed, cb.State())
+}
+
+func TestCircuitBreaker_ProbeFails_ReopensBreaker(t *testing.T) {
+	cb := newTestBreaker()
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, call(cb, true))
+	}
+
+	time.Sleep(40 * time.Millisecond)
+	assert.NoError(t, call(cb, true))
+
+	assert.Equal(t, breaker.Open, cb.State())
+}
+
+func TestCircuitBreaker_ReleasedProbe_FreesSlotWithoutClosing(t *testing.T) {
+	cb := newTestBreaker()
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, call(cb, true))
+	}
+
+	time.Sleep(40 * time.Millisecond)
+	first := allow(t, cb)
+	second := allow(t, cb)
+	_, err := cb.Allow()
+	assert.ErrorIs(t, err, breaker.ErrOpen)
+
+	cb.Release(first)
+	cb.Release(second)
+	assert.Equal(t, breaker.HalfOpen, cb.State(), "released probes are no evidence of recovery")
+
+	assert.NoError(t, call(cb, false))
+	assert.NoError(t, call(cb, false))
+	assert.Equal(t, breaker.Closed, cb.State())
+}
+
+func TestCircuitBreaker_OutcomeFromEarlierGeneration_IsIgnored(t *testing.T) {
+	cb := newTestBreaker()
+	slow := allow(t, cb)
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, call(cb, true))
+	}
+
+	time.Sleep(40 * time.Millisecond)
+	probe := allow(t, cb)
+
+	// A call admitted while closed finishes during half-open.
+	cb.Record(slow, false)
+	cb.Release(slow)
+	assert.Equal(t, breaker.HalfOpen, cb.State())
+
+	cb.Record(probe, false)
+	assert.Equal(t, breaker.HalfOpen, cb.State(), "one probe of two succeeded")
+	assert.NoError(t, call(cb, false))
+	assert.Equal(t, breaker.Closed, cb.State())
+}
@@ -0,0 +1,156 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vwency/resilient-scatter-gather/internal/cache"
+	"github.com/vwency/resilient-scatter-gather/internal/requestid"
+)
+
+func TestLookup_WithinTTL_ReturnsFresh(t *testing.T) {
+	c := cache.New[string, string](cache.Settings{TTL: time.Second, StaleTTL: time.Second})
+	c.Store("k", "v")
+
+	value, state := c.Lookup("k")
+
+	assert.Equal(t, cache.Fresh, state)
+	assert.Equal(t, "v", value)
+}
+
+func TestLookup_PastStaleTTL_ReturnsMiss(t *testing.T) {
+	c := cache.New[string, string](cache.Settings{TTL: 10 * time.Millisecond, StaleTTL: 10 * time.Millisecond})
+	c.Store("k", "v")
+
+	time.Sleep(15 * time.Millisecond)
+	_, state := c.Lookup("k")
+	assert.Equal(t, cache.Stale, state)
+
+	time.Sleep(10 * time.Millisecond)
+	_, state = c.Lookup("k")
+	assert.Equal(t, cache.Miss, state)
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestStore_MaxEntriesExceeded_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := cache.New[string, int](cache.Settings{TTL: time.Second, MaxEntries: 2})
+	c.Store("a", 1)
+	c.Store("b", 2)
+	c.Lookup("a")
+	c.Store("c", 3)
+
+	_, state := c.Lookup("b")
+	assert.Equal(t, cache.Miss, state)
+	_, state = c.Lookup("a")
+	assert.Equal(t, cache.Fresh, state)
+	assert.Equal(t, 2, c.Len())
+}
+
+func TestFetch_StaleEntryBackendDown_ServesStale(t *testing.T) {
+	c := cache.New[string, string](cache.Settings{TTL: 10 * time.Millisecond, StaleTTL: time.Second})
+	var loads atomic.Int32
+
+	value, state, err := cache.Fetch(context.Background(), c, "k", func(ctx context.Context) (string, error) {
+		loads.Add(1)
+		return "v1", nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, cache.Miss, state)
+	assert.Equal(t, "v1", value)
+
+	time.Sleep(15 * time.Millisecond)
+
+	value, state, err = cache.Fetch(context.Background(), c, "k", func(ctx context.Context) (string, error) {
+		loads.Add(1)
+		return "", errors.New("backend down")
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, cache.Stale, state)
+	assert.Equal(t, "v1", value)
+
+	assert.Eventually(t, func() bool { return loads.Load() == 2 }, time.Second, 5*time.Millisecond)
+	value, _ = c.Lookup("k")
+	assert.Equal(t, "v1", value)
+}
+
+func TestFetch_StaleEntry_RevalidatesInBackground(t *testing.T) {
+	c := cache.New[string, string](cache.Settings{TTL: 10 * time.Millisecond, StaleTTL: time.Second})
+	c.Store("k", "old")
+	time.Sleep(15 * time.Millisecond)
+
+	start := time.Now()
+	value, state, err := cache.Fetch(context.Background(), c, "k", func(ctx context.Context) (string, error) {
+		time.Sleep(50 * time.Millisecond)
+		return "new", nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, cache.Stale, state)
+	assert.Equal(t, "old", value)
+	assert.Less(t, time.Since(start), 20*time.Millisecond)
+
+	assert.Eventually(t, func() bool {
+		value, state := c.Lookup("k")
+		return state == cache.Fresh && value == "new"
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestFetch_StaleEntry_RefreshHasDefaultTimeout(t *testing.T) {
+	c := cache.New[string, string](cache.Settings{TTL: time.Millisecond, StaleTTL: time.Second})
+	c.Store("k", "old")
+	time.Sleep(5 * time.Millisecond)
+
+	remaining := make(chan time.Duration, 1)
+	_, state, err := cache.Fetch(context.Background(), c, "k", func(ctx context.Context) (string, error) {
+		deadline, ok := ctx.Deadline()
+		if !ok {
+			remaining <- 0
+			return "", errors.New("no deadline")
+		}
+		remaining <- time.Until(deadline)
+		return "new", nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, cache.Stale, state)
+	assert.InDelta(t, time.Second, <-remaining, float64(100*time.Millisecond))
+}
+
+type requestValueKey struct{}
+
+func TestFetch_StaleEntry_RefreshCarriesOnlyRequestID(t *testing.T) {
+	c := cache.New[string, string](cache.Settings{TTL: time.Millisecond, StaleTTL: time.Second})
+	c.Store("k", "old")
+	time.Sleep(5 * time.Millisecond)
+
+	ctx := requestid.NewContext(context.Background(), "req-1")
+	ctx = context.WithValue(ctx, requestValueKey{}, "leg state")
+
+	refreshed := make(chan context.Context, 1)
+	_, _, err := cache.Fetch(ctx, c, "k", func(ctx context.Context) (string, error) {
+		refreshed <- ctx
+		return "new", nil
+	})
+	assert.NoError(t, err)
+
+	refreshCtx := <-refreshed
+	id, ok := requestid.FromContext(refreshCtx)
+	assert.True(t, ok)
+	assert.Equal(t, "req-1", id)
+	assert.Nil(t, refreshCtx.Value(requestValueKey{}))
+}
+
+func TestFetch_MissBackendDown_ReturnsError(t *testing.T) {
+	c := cache.New[string, string](cache.Settings{TTL: time.Second})
+
+	_, _, err := cache.Fetch(context.Background(), c, "k", func(ctx context.Context) (string, error) {
+		return "", errors.New("backend down")
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, 0, c.Len())
+}
@@ -0,0 +1,180 @@
+package coalesce_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vwency/resilient-scatter-gather/internal/coalesce"
+)
+
+func TestDo_ConcurrentSameKey_RunsOnce(t *testing.T) {
+	g := coalesce.NewGroup[string, string]()
+	var calls atomic.Int32
+
+	fn := func(ctx context.Context) (string, error) {
+		calls.Add(1)
+		time.Sleep(30 * time.Millisecond)
+		return "value", nil
+	}
+
+	var wg sync.WaitGroup
+	var sharedCount atomic.Int32
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, shared, err := g.Do(context.Background(), "key", fn)
+			assert.NoError(t, err)
+			assert.Equal(t, "value", value)
+			if shared {
+				sharedCount.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+	assert.Equal(t, int32(9), sharedCount.Load())
+}
+
+func TestDo_DifferentKeys_RunSeparately(t *testing.T) {
+	g := coalesce.NewGroup[string, string]()
+	var calls atomic.Int32
+
+	fn := func(ctx context.Context) (string, error) {
+		calls.Add(1)
+		time.Sleep(10 * time.Millisecond)
+		return "value", nil
+	}
+
+	var wg sync.WaitGroup
+	for _, key := range []string{"a", "b"} {
+		wg.Add(1)
+		go func(key string) {
+			defer wg.Done()
+			_, _, err := g.Do(context.Background(), key, fn)
+			assert.NoError(t, err)
+		}(key)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestDo_FirstCallerCancelled_OthersStillGetResult(t *testing.T) {
+	g := coalesce.NewGroup[string, string]()
+	started := make(chan struct{})
+
+	fn := func(ctx context.Context) (string, error) {
+		close(started)
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(50 * time.Millisecond):
+			return "value", nil
+		}
+	}
+
+	firstCtx, cancelFirst := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, _, err := g.Do(firstCtx, "key", fn)
+		firstErr <- err
+	}()
+	<-started
+
+	secondValue := make(chan string, 1)
+	go func() {
+		value, _, err := g.Do(context.Background(), "key", fn)
+		assert.NoError(t, err)
+		secondValue <- value
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	cancelFirst()
+
+	assert.ErrorIs(t, <-firstErr, context.Canceled)
+	assert.Equal(t, "value", <-secondValue)
+}
+
+func TestDo_AllCallersCancelled_CancelsSharedCall(t *testing.T) {
+	g := coalesce.NewGroup[string, string]()
+	callErr := make(chan error, 1)
+
+	fn := func(ctx context.Context) (string, error) {
+		select {
+		case <-ctx.Done():
+			callErr <- ctx.Err()
+			return "", ctx.Err()
+		case <-time.After(time.Second):
+			callErr <- nil
+			return "value", nil
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, _, err := g.Do(ctx, "key", fn)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Error(t, <-callErr)
+}
+
+func TestDo_LaterCaller_ExtendsDeadline(t *testing.T) {
+	g := coalesce.NewGroup[string, string]()
+	started := make(chan struct{})
+
+	fn := func(ctx context.Context) (string, error) {
+		close(started)
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(60 * time.Millisecond):
+			return "value", nil
+		}
+	}
+
+	firstCtx, cancelFirst := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancelFirst()
+	firstErr := make(chan error, 1)
+	go func() {
+		_, _, err := g.Do(firstCtx, "key", fn)
+		firstErr <- err
+	}()
+	<-started
+
+	secondCtx, cancelSecond := context.WithTimeout(context.Background(), time.Second)
+	defer cancelSecond()
+	value, shared, err := g.Do(secondCtx, "key", fn)
+
+	assert.ErrorIs(t, <-firstErr, context.DeadlineExceeded)
+	assert.NoError(t, err)
+	assert.True(t, shared)
+	assert.Equal(t, "value", value)
+}
+
+func TestDo_SharedCallDeadline_ExpiresWithDeadlineExceeded(t *testing.T) {
+	g := coalesce.NewGroup[string, string]()
+	deadline := time.Now().Add(20 * time.Millisecond)
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+
+	var callDeadline time.Time
+	callErr := make(chan error, 1)
+	go func() {
+		_, _, _ = g.Do(ctx, "key", func(ctx context.Context) (string, error) {
+			callDeadline, _ = ctx.Deadline()
+			<-ctx.Done()
+			callErr <- ctx.Err()
+			return "", ctx.Err()
+		})
+	}()
+
+	assert.ErrorIs(t, <-callErr, context.DeadlineExceeded)
+	assert.Equal(t, deadline, callDeadline)
+}
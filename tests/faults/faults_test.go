@@ -0,0 +1,208 @@
+package faults_test
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vwency/resilient-scatter-gather/internal/faults"
+	pb_user "github.com/vwency/resilient-scatter-gather/proto/user"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+type countingUserServer struct {
+	pb_user.UnimplementedUserServiceServer
+	calls atomic.Int32
+}
+
+func (s *countingUserServer) GetUser(ctx context.Context, req *pb_user.GetUserRequest) (*pb_user.GetUserResponse, error) {
+	s.calls.Add(1)
+	return &pb_user.GetUserResponse{UserId: req.GetUserId()}, nil
+}
+
+func dialWithInjector(t *testing.T, injector *faults.Injector) (pb_user.UserServiceClient, *countingUserServer) {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	backend := &countingUserServer{}
+	server := grpc.NewServer()
+	pb_user.RegisterUserServiceServer(server, backend)
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient(lis.Addr().String(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(injector.UnaryClientInterceptor()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return pb_user.NewUserServiceClient(conn), backend
+}
+
+func mustParse(t *testing.T, specs ...faults.RuleSpec) []faults.Rule {
+	t.Helper()
+
+	rules, err := faults.ParseRules(specs)
+	require.NoError(t, err)
+	return rules
+}
+
+func TestParseRule_RejectsInvalidSpecs(t *testing.T) {
+	for _, spec := range []faults.RuleSpec{
+		{Percentage: 101},
+		{Percentage: -1},
+		{Percentage: 10, DelayMs: -5},
+		{Percentage: 10, Code: "NOT_A_CODE"},
+		{Percentage: 10, Code: "UNAVAILABLE", Drop: true},
+	} {
+		_, err := faults.ParseRule(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestParseRule_AcceptsBothCodeSpellings(t *testing.T) {
+	for _, name := range []string{"RESOURCE_EXHAUSTED", "resource_exhausted", "ResourceExhausted"} {
+		rule, err := faults.ParseRule(faults.RuleSpec{Percentage: 10, Code: name})
+		require.NoError(t, err, name)
+		assert.Equal(t, codes.ResourceExhausted, rule.Code, name)
+	}
+}
+
+func TestInterceptor_NoRules_PassesThrough(t *testing.T) {
+	client, backend := dialWithInjector(t, faults.New(nil))
+
+	resp, err := client.GetUser(context.Background(), &pb_user.GetUserRequest{UserId: "user123"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "user123", resp.GetUserId())
+	assert.Equal(t, int32(1), backend.calls.Load())
+}
+
+func TestInterceptor_ErrorRule_FailsWithoutCallingBackend(t *testing.T) {
+	client, backend := dialWithInjector(t, faults.New(mustParse(t, faults.RuleSpec{
+		Service: "user.UserService", Method: "GetUser", Percentage: 100, Code: "UNAVAILABLE",
+	})))
+
+	_, err := client.GetUser(context.Background(), &pb_user.GetUserRequest{UserId: "user123"})
+
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, int32(0), backend.calls.Load())
+}
+
+func TestInterceptor_RuleForOtherService_IsIgnored(t *testing.T) {
+	client, _ := dialWithInjector(t, faults.New(mustParse(t, faults.RuleSpec{
+		Service: "vector.VectorMemoryService", Percentage: 100, Code: "UNAVAILABLE",
+	})))
+
+	_, err := client.GetUser(context.Background(), &pb_user.GetUserRequest{UserId: "user123"})
+
+	assert.NoError(t, err)
+}
+
+func TestInterceptor_CatchAllRule_SkipsHealthChecksUnlessNamed(t *testing.T) {
+	invoker := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return nil
+	}
+	check := func(injector *faults.Injector) error {
+		return injector.UnaryClientInterceptor()(context.Background(), "/grpc.health.v1.Health/Check", nil, nil, nil, invoker)
+	}
+
+	catchAll := faults.New(mustParse(t, faults.RuleSpec{Percentage: 100, Code: "UNAVAILABLE"}))
+	assert.NoError(t, check(catchAll))
+
+	named := faults.New(mustParse(t, faults.RuleSpec{Service: "grpc.health.v1.Health", Percentage: 100, Code: "UNAVAILABLE"}))
+	assert.Equal(t, codes.Unavailable, status.Code(check(named)))
+}
+
+func TestInterceptor_DelayRule_DelaysCall(t *testing.T) {
+	client, _ := dialWithInjector(t, faults.New(mustParse(t, faults.RuleSpec{Percentage: 100, DelayMs: 40})))
+
+	start := time.Now()
+	_, err := client.GetUser(context.Background(), &pb_user.GetUserRequest{UserId: "user123"})
+
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = client.GetUser(ctx, &pb_user.GetUserRequest{UserId: "user123"})
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+}
+
+func TestInterceptor_DropRule_CallsBackendButTimesOut(t *testing.T) {
+	client, backend := dialWithInjector(t, faults.New(mustParse(t, faults.RuleSpec{Percentage: 100, Drop: true})))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	_, err := client.GetUser(ctx, &pb_user.GetUserRequest{UserId: "user123"})
+
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	assert.Equal(t, int32(1), backend.calls.Load())
+}
+
+func TestInterceptor_Percentage_AppliesToShareOfCalls(t *testing.T) {
+	client, _ := dialWithInjector(t, faults.New(mustParse(t, faults.RuleSpec{Percentage: 25, Code: "INTERNAL"})))
+
+	failures := 0
+	for i := 0; i < 400; i++ {
+		if _, err := client.GetUser(context.Background(), &pb_user.GetUserRequest{UserId: "user123"}); err != nil {
+			failures++
+		}
+	}
+
+	assert.InDelta(t, 100, failures, 40)
+}
+
+func TestAdminHandler_ReplacesListsAndClearsRules(t *testing.T) {
+	injector := faults.New(nil)
+	client, _ := dialWithInjector(t, injector)
+	h := faults.AdminHandler(injector)
+
+	body := `{"rules":[{"service":"user.UserService","percentage":100,"code":"UNAVAILABLE"}]}`
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/admin/faults", strings.NewReader(body)))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	_, err := client.GetUser(context.Background(), &pb_user.GetUserRequest{UserId: "user123"})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/faults", nil))
+	var doc struct {
+		Rules []faults.RuleSpec `json:"rules"`
+	}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&doc))
+	assert.Equal(t, []faults.RuleSpec{{Service: "user.UserService", Percentage: 100, Code: "Unavailable"}}, doc.Rules)
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/admin/faults", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	_, err = client.GetUser(context.Background(), &pb_user.GetUserRequest{UserId: "user123"})
+	assert.NoError(t, err)
+}
+
+func TestAdminHandler_InvalidRules_Returns400AndKeepsRules(t *testing.T) {
+	injector := faults.New(mustParse(t, faults.RuleSpec{Percentage: 50}))
+	h := faults.AdminHandler(injector)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/admin/faults", strings.NewReader(`{"rules":[{"percentage":500}]}`)))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Len(t, injector.Rules(), 1)
+}
@@ -0,0 +1,28 @@
+package grpccode_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vwency/resilient-scatter-gather/internal/grpccode"
+	"google.golang.org/grpc/codes"
+)
+
+func TestParse_AcceptsEverySpelling(t *testing.T) {
+	for _, name := range []string{"RESOURCE_EXHAUSTED", "resource_exhausted", "ResourceExhausted", "resourceexhausted"} {
+		code, err := grpccode.Parse(name)
+		assert.NoError(t, err, name)
+		assert.Equal(t, codes.ResourceExhausted, code, name)
+	}
+
+	code, err := grpccode.Parse("Canceled")
+	assert.NoError(t, err)
+	assert.Equal(t, codes.Canceled, code)
+}
+
+func TestParse_InvalidName_ReturnsError(t *testing.T) {
+	for _, name := range []string{"", "NOT_A_CODE", "1"} {
+		_, err := grpccode.Parse(name)
+		assert.Error(t, err, name)
+	}
+}
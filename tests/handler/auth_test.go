@@ -0,0 +1,83 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/vwency/resilient-scatter-gather/internal/auth"
+	"github.com/vwency/resilient-scatter-gather/internal/handler"
+	pb_permissions "github.com/vwency/resilient-scatter-gather/proto/permissions"
+	pb_user "github.com/vwency/resilient-scatter-gather/proto/user"
+	pb_vector "github.com/vwency/resilient-scatter-gather/proto/vector"
+)
+
+func withIdentity(req *http.Request, subject string) *http.Request {
+	return req.WithContext(auth.NewContext(req.Context(), auth.Identity{Subject: subject}))
+}
+
+func TestServeHTTP_AuthenticatedIdentity_ReplacesUserIDParameter(t *testing.T) {
+	mockUser := new(UserService)
+	mockPermissions := new(PermissionsService)
+	mockVector := new(VectorMemoryService)
+
+	mockUser.On("GetUser", mock.Anything, "alice").Return(&pb_user.GetUserResponse{UserId: "alice"}, nil)
+	mockPermissions.On("CheckAccess", mock.Anything, "alice", "chat1").Return(&pb_permissions.CheckAccessResponse{Allowed: true}, nil)
+	mockVector.On("GetContext", mock.Anything, "chat1").Return(&pb_vector.GetContextResponse{}, nil)
+
+	h := handler.NewChatSummaryHandler(mockUser, mockVector, mockPermissions, 200*time.Millisecond)
+
+	req := withIdentity(httptest.NewRequest("GET", "/api/v1/chat/summary?chat_id=chat1", nil), "alice")
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockUser.AssertExpectations(t)
+	mockPermissions.AssertExpectations(t)
+}
+
+func TestServeHTTP_UserIDOfAnotherUser_Returns403(t *testing.T) {
+	mockUser := new(UserService)
+	mockPermissions := new(PermissionsService)
+	mockVector := new(VectorMemoryService)
+
+	h := handler.NewChatSummaryHandler(mockUser, mockVector, mockPermissions, 200*time.Millisecond)
+
+	req := withIdentity(httptest.NewRequest("GET", "/api/v1/chat/summary?user_id=bob&chat_id=chat1", nil), "alice")
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "does not match the authenticated user")
+	mockUser.AssertNotCalled(t, "GetUser", mock.Anything, mock.Anything)
+}
+
+func TestBatchServeHTTP_AuthenticatedIdentity_IsUsedForEveryChat(t *testing.T) {
+	mockUser := new(UserService)
+	mockPermissions := new(PermissionsService)
+	mockVector := new(VectorMemoryService)
+
+	mockUser.On("GetUser", mock.Anything, "alice").Return(&pb_user.GetUserResponse{UserId: "alice"}, nil)
+	mockPermissions.On("CheckAccess", mock.Anything, "alice", mock.Anything).Return(&pb_permissions.CheckAccessResponse{Allowed: true}, nil)
+	mockVector.On("GetContext", mock.Anything, mock.Anything).Return(&pb_vector.GetContextResponse{}, nil)
+
+	summary := handler.NewChatSummaryHandler(mockUser, mockVector, mockPermissions, 200*time.Millisecond)
+	h := handler.NewBatchChatSummaryHandler(summary, handler.BatchSettings{})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, withIdentity(newBatchRequest(`{"chat_ids":["c1","c2"]}`), "alice"))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockPermissions.AssertNumberOfCalls(t, "CheckAccess", 2)
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, withIdentity(newBatchRequest(`{"user_id":"bob","chat_ids":["c1"]}`), "alice"))
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "does not match")
+}
@@ -0,0 +1,225 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/vwency/resilient-scatter-gather/internal/handler"
+	"github.com/vwency/resilient-scatter-gather/internal/metrics"
+	"github.com/vwency/resilient-scatter-gather/internal/models"
+	pb_permissions "github.com/vwency/resilient-scatter-gather/proto/permissions"
+	pb_user "github.com/vwency/resilient-scatter-gather/proto/user"
+	pb_vector "github.com/vwency/resilient-scatter-gather/proto/vector"
+)
+
+func newBatchRequest(body string) *http.Request {
+	return httptest.NewRequest(http.MethodPost, "/api/v1/chat/summaries", strings.NewReader(body))
+}
+
+func TestBatchServeHTTP_MixedOutcomes_ReportsPerItemStatus(t *testing.T) {
+	mockUser := new(UserService)
+	mockPermissions := new(PermissionsService)
+	mockVector := new(VectorMemoryService)
+
+	userResp := &pb_user.GetUserResponse{UserId: "user123"}
+	mockUser.On("GetUser", mock.Anything, "user123").Return(userResp, nil).Once()
+
+	allowed := &pb_permissions.CheckAccessResponse{Allowed: true}
+	mockPermissions.On("CheckAccess", mock.Anything, "user123", "chat-ok").Return(allowed, nil)
+	mockPermissions.On("CheckAccess", mock.Anything, "user123", "chat-denied").Return(&pb_permissions.CheckAccessResponse{Allowed: false, Reason: "not a member"}, nil)
+	mockPermissions.On("CheckAccess", mock.Anything, "user123", "chat-degraded").Return(allowed, nil)
+	mockPermissions.On("CheckAccess", mock.Anything, "user123", "chat-broken").Return(nil, errors.New("permissions down"))
+
+	vectorResp := &pb_vector.GetContextResponse{TotalCount: 2}
+	mockVector.On("GetContext", mock.Anything, "chat-ok").Return(vectorResp, nil)
+	mockVector.On("GetContext", mock.Anything, "chat-denied").Return(vectorResp, nil).Maybe()
+	mockVector.On("GetContext", mock.Anything, "chat-degraded").Return(nil, errors.New("vector down"))
+	mockVector.On("GetContext", mock.Anything, "chat-broken").Return(vectorResp, nil).Maybe()
+
+	summary := handler.NewChatSummaryHandler(mockUser, mockVector, mockPermissions, 200*time.Millisecond)
+	h := handler.NewBatchChatSummaryHandler(summary, handler.BatchSettings{Concurrency: 2})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, newBatchRequest(`{"user_id":"user123","chat_ids":["chat-ok","chat-denied","chat-degraded","chat-broken"]}`))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEmpty(t, w.Header().Get("X-Request-ID"))
+
+	var response models.BatchChatSummaryResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, "user123", response.User.GetUserId())
+	assert.True(t, response.Degraded)
+	require.Len(t, response.Items, 4)
+
+	ok := response.Items[0]
+	assert.Equal(t, "chat-ok", ok.ChatID)
+	assert.Equal(t, "ok", ok.Status)
+	assert.Equal(t, http.StatusOK, ok.Code)
+	assert.True(t, ok.Permissions.GetAllowed())
+	assert.Equal(t, int32(2), ok.Context.GetTotalCount())
+
+	denied := response.Items[1]
+	assert.Equal(t, "forbidden", denied.Status)
+	assert.Equal(t, http.StatusForbidden, denied.Code)
+	assert.Equal(t, "not a member", denied.Error)
+	assert.Nil(t, denied.Context)
+
+	degraded := response.Items[2]
+	assert.Equal(t, "degraded", degraded.Status)
+	assert.True(t, degraded.Degraded)
+	assert.Nil(t, degraded.Context)
+	assert.Equal(t, "error", degraded.Legs["vector"].Status)
+
+	broken := response.Items[3]
+	assert.Equal(t, "error", broken.Status)
+	assert.Equal(t, http.StatusInternalServerError, broken.Code)
+	assert.Contains(t, broken.Error, "permissions down")
+	assert.Nil(t, broken.Permissions)
+
+	mockUser.AssertNumberOfCalls(t, "GetUser", 1)
+}
+
+func TestBatchServeHTTP_UserServiceError_Returns500(t *testing.T) {
+	mockUser := new(UserService)
+	mockPermissions := new(PermissionsService)
+	mockVector := new(VectorMemoryService)
+
+	mockUser.On("GetUser", mock.Anything, "user123").Return(nil, errors.New("user service down"))
+	mockPermissions.On("CheckAccess", mock.Anything, "user123", mock.Anything).Return(&pb_permissions.CheckAccessResponse{Allowed: true}, nil).Maybe()
+	mockVector.On("GetContext", mock.Anything, mock.Anything).Return(&pb_vector.GetContextResponse{}, nil).Maybe()
+
+	summary := handler.NewChatSummaryHandler(mockUser, mockVector, mockPermissions, 200*time.Millisecond)
+	h := handler.NewBatchChatSummaryHandler(summary, handler.BatchSettings{})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, newBatchRequest(`{"user_id":"user123","chat_ids":["chat1","chat2"]}`))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	var errResp models.ErrorResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&errResp))
+	assert.Contains(t, errResp.Message, "user service down")
+}
+
+func TestBatchServeHTTP_InvalidRequests(t *testing.T) {
+	summary := handler.NewChatSummaryHandler(new(UserService), new(VectorMemoryService), new(PermissionsService), 200*time.Millisecond)
+	h := handler.NewBatchChatSummaryHandler(summary, handler.BatchSettings{MaxItems: 2})
+
+	tests := []struct {
+		name   string
+		req    *http.Request
+		status int
+	}{
+		{name: "wrong method", req: httptest.NewRequest(http.MethodGet, "/api/v1/chat/summaries", nil), status: http.StatusMethodNotAllowed},
+		{name: "malformed body", req: newBatchRequest(`{"user_id":`), status: http.StatusBadRequest},
+		{name: "missing user", req: newBatchRequest(`{"chat_ids":["chat1"]}`), status: http.StatusBadRequest},
+		{name: "no chats", req: newBatchRequest(`{"user_id":"user123","chat_ids":[]}`), status: http.StatusBadRequest},
+		{name: "empty chat id", req: newBatchRequest(`{"user_id":"user123","chat_ids":[""]}`), status: http.StatusBadRequest},
+		{name: "too many chats", req: newBatchRequest(`{"user_id":"user123","chat_ids":["a","b","c"]}`), status: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, tt.req)
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestBatchServeHTTP_BoundsConcurrentChats(t *testing.T) {
+	mockUser := new(UserService)
+	mockPermissions := new(PermissionsService)
+	mockVector := new(VectorMemoryService)
+
+	mockUser.On("GetUser", mock.Anything, "user123").Return(&pb_user.GetUserResponse{UserId: "user123"}, nil)
+
+	var inFlight, peak atomic.Int32
+	mockPermissions.On("CheckAccess", mock.Anything, "user123", mock.Anything).Return(&pb_permissions.CheckAccessResponse{Allowed: true}, nil).Run(func(args mock.Arguments) {
+		current := inFlight.Add(1)
+		for {
+			seen := peak.Load()
+			if current <= seen || peak.CompareAndSwap(seen, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		inFlight.Add(-1)
+	})
+	mockVector.On("GetContext", mock.Anything, mock.Anything).Return(&pb_vector.GetContextResponse{}, nil)
+
+	summary := handler.NewChatSummaryHandler(mockUser, mockVector, mockPermissions, 200*time.Millisecond)
+	h := handler.NewBatchChatSummaryHandler(summary, handler.BatchSettings{Concurrency: 3, Timeout: time.Second})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, newBatchRequest(`{"user_id":"user123","chat_ids":["c1","c2","c3","c4","c5","c6","c7","c8","c9","c10"]}`))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.LessOrEqual(t, peak.Load(), int32(3))
+	mockPermissions.AssertNumberOfCalls(t, "CheckAccess", 10)
+}
+
+func TestBatchServeHTTP_SlowVector_ReturnsDegradedItems(t *testing.T) {
+	mockUser := new(UserService)
+	mockPermissions := new(PermissionsService)
+	mockVector := new(VectorMemoryService)
+
+	mockUser.On("GetUser", mock.Anything, "user123").Return(&pb_user.GetUserResponse{UserId: "user123"}, nil)
+	mockPermissions.On("CheckAccess", mock.Anything, "user123", mock.Anything).Return(&pb_permissions.CheckAccessResponse{Allowed: true}, nil)
+	mockVector.On("GetContext", mock.Anything, mock.Anything).Return(nil, context.DeadlineExceeded).Run(func(args mock.Arguments) {
+		<-args.Get(0).(context.Context).Done()
+	})
+
+	summary := handler.NewChatSummaryHandler(mockUser, mockVector, mockPermissions, 100*time.Millisecond)
+	h := handler.NewBatchChatSummaryHandler(summary, handler.BatchSettings{})
+
+	for range 10 {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, newBatchRequest(`{"user_id":"user123","chat_ids":["chat1","chat2"]}`))
+
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var response models.BatchChatSummaryResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		assert.True(t, response.Degraded)
+		require.Len(t, response.Items, 2)
+		for _, item := range response.Items {
+			assert.Equal(t, "degraded", item.Status)
+			assert.Equal(t, "timeout", item.Legs["vector"].Status)
+		}
+	}
+}
+
+func TestBatchServeHTTP_WithMetrics_RecordsRequests(t *testing.T) {
+	mockUser := new(UserService)
+	mockPermissions := new(PermissionsService)
+	mockVector := new(VectorMemoryService)
+
+	mockUser.On("GetUser", mock.Anything, "user123").Return(&pb_user.GetUserResponse{UserId: "user123"}, nil)
+	mockPermissions.On("CheckAccess", mock.Anything, "user123", "chat1").Return(&pb_permissions.CheckAccessResponse{Allowed: true}, nil)
+	mockVector.On("GetContext", mock.Anything, "chat1").Return(&pb_vector.GetContextResponse{}, nil)
+
+	reg := prometheus.NewRegistry()
+	m := metrics.New(reg)
+	summary := handler.NewChatSummaryHandler(mockUser, mockVector, mockPermissions, 200*time.Millisecond, handler.WithMetrics(m))
+	h := handler.NewBatchChatSummaryHandler(summary, handler.BatchSettings{})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, newBatchRequest(`{"user_id":"user123","chat_ids":["chat1"]}`))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1.0, metricValue(t, reg, "gateway_http_requests_total", map[string]string{"endpoint": "batch_summary", "status": "200"}))
+	assert.Equal(t, 1.0, metricValue(t, reg, "gateway_http_request_duration_seconds", map[string]string{"endpoint": "batch_summary", "status": "200"}))
+	assert.Equal(t, 0.0, metricValue(t, reg, "gateway_http_request_duration_seconds", map[string]string{"endpoint": "summary"}))
+	assert.Equal(t, 0.0, metricValue(t, reg, "gateway_http_requests_in_flight", nil))
+}
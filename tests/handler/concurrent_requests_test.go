@@ -13,6 +13,7 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/vwency/resilient-scatter-gather/internal/handler"
 	"github.com/vwency/resilient-scatter-gather/internal/models"
+	"github.com/vwency/resilient-scatter-gather/internal/services"
 	pb_permissions "github.com/vwency/resilient-scatter-gather/proto/permissions"
 	pb_user "github.com/vwency/resilient-scatter-gather/proto/user"
 	pb_vector "github.com/vwency/resilient-scatter-gather/proto/vector"
@@ -173,3 +174,60 @@ func TestServeHTTP_ConcurrentRequestsMixedScenarios_HandleCorrectly(t *testing.T
 	assert.True(t, results[0], "chat1 should succeed without degradation")
 	assert.True(t, results[1], "chat2 should be degraded")
 }
+
+func TestServeHTTP_ConcurrentIdenticalRequestsCoalesced_SingleCallPerBackend(t *testing.T) {
+	mockUser := new(UserService)
+	mockPermissions := new(PermissionsService)
+	mockVector := new(VectorMemoryService)
+
+	userResp := &pb_user.GetUserResponse{UserId: "user123", Username: "testuser"}
+	mockUser.On("GetUser", mock.Anything, "user123").Return(userResp, nil).Run(func(args mock.Arguments) {
+		time.Sleep(50 * time.Millisecond)
+	}).Once()
+
+	permResp := &pb_permissions.CheckAccessResponse{Allowed: true}
+	mockPermissions.On("CheckAccess", mock.Anything, "user123", "chat1").Return(permResp, nil).Run(func(args mock.Arguments) {
+		time.Sleep(50 * time.Millisecond)
+	}).Once()
+
+	vectorResp := &pb_vector.GetContextResponse{Items: []*pb_vector.ContextItem{{Content: "ctx"}}}
+	mockVector.On("GetContext", mock.Anything, "chat1").Return(vectorResp, nil).Run(func(args mock.Arguments) {
+		time.Sleep(80 * time.Millisecond)
+	}).Once()
+
+	h := handler.NewChatSummaryHandler(
+		services.NewUserServiceCoalescer(mockUser),
+		services.NewVectorMemoryServiceCoalescer(mockVector),
+		services.NewPermissionsServiceCoalescer(mockPermissions),
+		200*time.Millisecond,
+	)
+
+	numRequests := 10
+	var wg sync.WaitGroup
+	wg.Add(numRequests)
+
+	results := make([]int, numRequests)
+
+	for i := 0; i < numRequests; i++ {
+		go func(index int) {
+			defer wg.Done()
+
+			req := httptest.NewRequest("GET", "/api/chat-summary?user_id=user123&chat_id=chat1", nil)
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, req)
+
+			results[index] = w.Code
+		}(i)
+	}
+
+	wg.Wait()
+
+	for i, code := range results {
+		assert.Equal(t, http.StatusOK, code, "Request %d failed", i)
+	}
+
+	mockUser.AssertExpectations(t)
+	mockPermissions.AssertExpectations(t)
+	mockVector.AssertExpectations(t)
+}
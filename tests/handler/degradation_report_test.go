@@ -0,0 +1,149 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/vwency/resilient-scatter-gather/internal/cache"
+	"github.com/vwency/resilient-scatter-gather/internal/handler"
+	"github.com/vwency/resilient-scatter-gather/internal/models"
+	"github.com/vwency/resilient-scatter-gather/internal/services"
+	pb_permissions "github.com/vwency/resilient-scatter-gather/proto/permissions"
+	pb_user "github.com/vwency/resilient-scatter-gather/proto/user"
+	pb_vector "github.com/vwency/resilient-scatter-gather/proto/vector"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestServeHTTP_VectorTimeout_ReportsTimeoutPerLeg(t *testing.T) {
+	mockUser := new(UserService)
+	mockPermissions := new(PermissionsService)
+	mockVector := new(VectorMemoryService)
+
+	userResp := &pb_user.GetUserResponse{UserId: "user123"}
+	mockUser.On("GetUser", mock.Anything, "user123").Return(userResp, nil)
+
+	permResp := &pb_permissions.CheckAccessResponse{Allowed: true}
+	mockPermissions.On("CheckAccess", mock.Anything, "user123", "chat1").Return(permResp, nil)
+
+	mockVector.On("GetContext", mock.Anything, "chat1").Return(nil, context.DeadlineExceeded).Run(func(args mock.Arguments) {
+		time.Sleep(300 * time.Millisecond)
+	})
+
+	h := handler.NewChatSummaryHandler(mockUser, mockVector, mockPermissions, 100*time.Millisecond)
+
+	req := httptest.NewRequest("GET", "/api/chat-summary?user_id=user123&chat_id=chat1", nil)
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "true", w.Header().Get("X-Degraded"))
+	assert.Equal(t, "user=ok, permissions=ok, vector=timeout", w.Header().Get("X-Leg-Status"))
+
+	var response models.ChatSummaryResponse
+	err := json.NewDecoder(w.Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.True(t, response.Degraded)
+	assert.Equal(t, "ok", response.Legs["user"].Status)
+	assert.Equal(t, "ok", response.Legs["permissions"].Status)
+	assert.Equal(t, "timeout", response.Legs["vector"].Status)
+	assert.Equal(t, "DeadlineExceeded", response.Legs["vector"].ErrorCode)
+	assert.GreaterOrEqual(t, response.Legs["vector"].LatencyMs, 100.0)
+}
+
+func TestServeHTTP_VectorUnavailable_ReportsErrorCode(t *testing.T) {
+	mockUser := new(UserService)
+	mockPermissions := new(PermissionsService)
+	mockVector := new(VectorMemoryService)
+
+	userResp := &pb_user.GetUserResponse{UserId: "user123"}
+	mockUser.On("GetUser", mock.Anything, "user123").Return(userResp, nil)
+
+	permResp := &pb_permissions.CheckAccessResponse{Allowed: true}
+	mockPermissions.On("CheckAccess", mock.Anything, "user123", "chat1").Return(permResp, nil)
+
+	mockVector.On("GetContext", mock.Anything, "chat1").Return(nil, status.Error(codes.Unavailable, "connection refused"))
+
+	h := handler.NewChatSummaryHandler(mockUser, mockVector, mockPermissions, 200*time.Millisecond)
+
+	req := httptest.NewRequest("GET", "/api/chat-summary?user_id=user123&chat_id=chat1", nil)
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.ChatSummaryResponse
+	err := json.NewDecoder(w.Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.Equal(t, "error", response.Legs["vector"].Status)
+	assert.Equal(t, "Unavailable", response.Legs["vector"].ErrorCode)
+	assert.Empty(t, response.Legs["user"].ErrorCode)
+}
+
+func TestServeHTTP_UserServedFromCache_ReportsCached(t *testing.T) {
+	mockUser := new(UserService)
+	mockPermissions := new(PermissionsService)
+	mockVector := new(VectorMemoryService)
+
+	userResp := &pb_user.GetUserResponse{UserId: "user123"}
+	mockUser.On("GetUser", mock.Anything, "user123").Return(userResp, nil).Once()
+
+	permResp := &pb_permissions.CheckAccessResponse{Allowed: true}
+	mockPermissions.On("CheckAccess", mock.Anything, "user123", "chat1").Return(permResp, nil)
+
+	vectorResp := &pb_vector.GetContextResponse{Items: []*pb_vector.ContextItem{}}
+	mockVector.On("GetContext", mock.Anything, "chat1").Return(vectorResp, nil)
+
+	userService := services.NewUserServiceCache(mockUser, cache.Settings{TTL: time.Minute, MaxEntries: 10})
+	h := handler.NewChatSummaryHandler(userService, mockVector, mockPermissions, 200*time.Millisecond)
+
+	var response models.ChatSummaryResponse
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest("GET", "/api/chat-summary?user_id=user123&chat_id=chat1", nil)
+		w := httptest.NewRecorder()
+
+		h.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		err := json.NewDecoder(w.Body).Decode(&response)
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, "cached", response.Legs["user"].Status)
+	assert.Equal(t, "ok", response.Legs["permissions"].Status)
+	assert.False(t, response.Degraded)
+	mockUser.AssertExpectations(t)
+}
+
+func TestServeHTTP_UserServiceFails_HeadersReportSkippedLegs(t *testing.T) {
+	mockUser := new(UserService)
+	mockPermissions := new(PermissionsService)
+	mockVector := new(VectorMemoryService)
+
+	mockUser.On("GetUser", mock.Anything, "user123").Return(nil, errors.New("user service down"))
+
+	permResp := &pb_permissions.CheckAccessResponse{Allowed: true}
+	mockPermissions.On("CheckAccess", mock.Anything, "user123", "chat1").Return(permResp, nil).Run(blockUntilDone(make(chan error, 1))).Maybe()
+
+	vectorResp := &pb_vector.GetContextResponse{Items: []*pb_vector.ContextItem{}}
+	mockVector.On("GetContext", mock.Anything, "chat1").Return(vectorResp, nil).Run(blockUntilDone(make(chan error, 1))).Maybe()
+
+	h := handler.NewChatSummaryHandler(mockUser, mockVector, mockPermissions, 200*time.Millisecond)
+
+	req := httptest.NewRequest("GET", "/api/chat-summary?user_id=user123&chat_id=chat1", nil)
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "user=error, permissions=skipped, vector=skipped", w.Header().Get("X-Leg-Status"))
+}
@@ -0,0 +1,114 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/vwency/resilient-scatter-gather/internal/handler"
+	pb_permissions "github.com/vwency/resilient-scatter-gather/proto/permissions"
+	pb_user "github.com/vwency/resilient-scatter-gather/proto/user"
+	pb_vector "github.com/vwency/resilient-scatter-gather/proto/vector"
+)
+
+// blockUntilDone simulates a gRPC call that only returns once its context
+// is cancelled, reporting the cancellation cause on done.
+func blockUntilDone(done chan<- error) func(args mock.Arguments) {
+	return func(args mock.Arguments) {
+		ctx := args.Get(0).(context.Context)
+		select {
+		case <-ctx.Done():
+			done <- ctx.Err()
+		case <-time.After(5 * time.Second):
+			done <- nil
+		}
+	}
+}
+
+func waitForGoroutines(t *testing.T, baseline int) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if runtime.NumGoroutine() <= baseline {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("goroutines leaked: baseline %d, now %d", baseline, runtime.NumGoroutine())
+}
+
+func TestServeHTTP_UserServiceFails_CancelsInFlightLegs(t *testing.T) {
+	mockUser := new(UserService)
+	mockPermissions := new(PermissionsService)
+	mockVector := new(VectorMemoryService)
+
+	permissionsDone := make(chan error, 1)
+	vectorDone := make(chan error, 1)
+
+	mockUser.On("GetUser", mock.Anything, "user123").Return(nil, errors.New("user service down")).Run(func(args mock.Arguments) {
+		time.Sleep(10 * time.Millisecond)
+	})
+
+	permResp := &pb_permissions.CheckAccessResponse{Allowed: true}
+	mockPermissions.On("CheckAccess", mock.Anything, "user123", "chat1").Return(permResp, nil).Run(blockUntilDone(permissionsDone))
+
+	vectorResp := &pb_vector.GetContextResponse{Items: []*pb_vector.ContextItem{}}
+	mockVector.On("GetContext", mock.Anything, "chat1").Return(vectorResp, nil).Run(blockUntilDone(vectorDone))
+
+	h := handler.NewChatSummaryHandler(mockUser, mockVector, mockPermissions, 200*time.Millisecond)
+
+	baseline := runtime.NumGoroutine()
+
+	req := httptest.NewRequest("GET", "/api/chat-summary?user_id=user123&chat_id=chat1", nil)
+	w := httptest.NewRecorder()
+
+	start := time.Now()
+	h.ServeHTTP(w, req)
+	elapsed := time.Since(start)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Less(t, elapsed, 100*time.Millisecond)
+
+	assert.ErrorIs(t, <-permissionsDone, context.Canceled)
+	assert.ErrorIs(t, <-vectorDone, context.Canceled)
+
+	waitForGoroutines(t, baseline)
+}
+
+func TestServeHTTP_SLAExpires_NoGoroutineOutlivesRequest(t *testing.T) {
+	mockUser := new(UserService)
+	mockPermissions := new(PermissionsService)
+	mockVector := new(VectorMemoryService)
+
+	vectorDone := make(chan error, 1)
+
+	userResp := &pb_user.GetUserResponse{UserId: "user123"}
+	mockUser.On("GetUser", mock.Anything, "user123").Return(userResp, nil)
+
+	permResp := &pb_permissions.CheckAccessResponse{Allowed: true}
+	mockPermissions.On("CheckAccess", mock.Anything, "user123", "chat1").Return(permResp, nil)
+
+	vectorResp := &pb_vector.GetContextResponse{Items: []*pb_vector.ContextItem{}}
+	mockVector.On("GetContext", mock.Anything, "chat1").Return(vectorResp, nil).Run(blockUntilDone(vectorDone))
+
+	h := handler.NewChatSummaryHandler(mockUser, mockVector, mockPermissions, 50*time.Millisecond)
+
+	baseline := runtime.NumGoroutine()
+
+	req := httptest.NewRequest("GET", "/api/chat-summary?user_id=user123&chat_id=chat1", nil)
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Error(t, <-vectorDone)
+
+	waitForGoroutines(t, baseline)
+}
@@ -0,0 +1,83 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/vwency/resilient-scatter-gather/internal/handler"
+	"github.com/vwency/resilient-scatter-gather/internal/models"
+	"github.com/vwency/resilient-scatter-gather/internal/scatter"
+	pb_permissions "github.com/vwency/resilient-scatter-gather/proto/permissions"
+	pb_user "github.com/vwency/resilient-scatter-gather/proto/user"
+	pb_vector "github.com/vwency/resilient-scatter-gather/proto/vector"
+)
+
+func TestServeHTTP_LegBudgets_BoundLegsWithinReservedSLA(t *testing.T) {
+	mockUser := new(UserService)
+	mockPermissions := new(PermissionsService)
+	mockVector := new(VectorMemoryService)
+
+	remaining := func(args mock.Arguments) time.Duration {
+		deadline, ok := args.Get(0).(context.Context).Deadline()
+		require.True(t, ok)
+		return time.Until(deadline)
+	}
+
+	var userBudget, vectorBudget time.Duration
+	mockUser.On("GetUser", mock.Anything, "user123").Return(&pb_user.GetUserResponse{UserId: "user123"}, nil).Run(func(args mock.Arguments) {
+		userBudget = remaining(args)
+	})
+	mockPermissions.On("CheckAccess", mock.Anything, "user123", "chat1").Return(&pb_permissions.CheckAccessResponse{Allowed: true}, nil)
+	mockVector.On("GetContext", mock.Anything, "chat1").Return(&pb_vector.GetContextResponse{}, nil).Run(func(args mock.Arguments) {
+		vectorBudget = remaining(args)
+	})
+
+	h := handler.NewChatSummaryHandler(mockUser, mockVector, mockPermissions, 200*time.Millisecond, handler.WithBudgets(handler.Budgets{
+		EncodingReserve: 50 * time.Millisecond,
+		User:            scatter.Budget{Share: 0.5, Ceiling: 20 * time.Millisecond},
+	}))
+
+	req := httptest.NewRequest("GET", "/api/chat-summary?user_id=user123&chat_id=chat1", nil)
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.InDelta(t, 20*time.Millisecond, userBudget, float64(10*time.Millisecond))
+	assert.InDelta(t, 150*time.Millisecond, vectorBudget, float64(20*time.Millisecond), "legs without a budget end at the reserve")
+}
+
+func TestServeHTTP_LegBelowMinUseful_IsSkippedAndDegrades(t *testing.T) {
+	mockUser := new(UserService)
+	mockPermissions := new(PermissionsService)
+	mockVector := new(VectorMemoryService)
+
+	mockUser.On("GetUser", mock.Anything, "user123").Return(&pb_user.GetUserResponse{UserId: "user123"}, nil)
+	mockPermissions.On("CheckAccess", mock.Anything, "user123", "chat1").Return(&pb_permissions.CheckAccessResponse{Allowed: true}, nil)
+
+	h := handler.NewChatSummaryHandler(mockUser, mockVector, mockPermissions, 100*time.Millisecond, handler.WithBudgets(handler.Budgets{
+		Vector: scatter.Budget{MinUseful: 200 * time.Millisecond},
+	}))
+
+	req := httptest.NewRequest("GET", "/api/chat-summary?user_id=user123&chat_id=chat1", nil)
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("X-Leg-Status"), "vector=skipped")
+
+	var response models.ChatSummaryResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.True(t, response.Degraded)
+	assert.Equal(t, "skipped", response.Legs["vector"].Status)
+	assert.Equal(t, "DeadlineExceeded", response.Legs["vector"].ErrorCode)
+	mockVector.AssertNotCalled(t, "GetContext", mock.Anything, mock.Anything)
+}
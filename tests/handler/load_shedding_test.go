@@ -0,0 +1,68 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/vwency/resilient-scatter-gather/internal/breaker"
+	"github.com/vwency/resilient-scatter-gather/internal/handler"
+	"github.com/vwency/resilient-scatter-gather/internal/loadshed"
+	pb_permissions "github.com/vwency/resilient-scatter-gather/proto/permissions"
+	pb_user "github.com/vwency/resilient-scatter-gather/proto/user"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestServeHTTP_BehindLoadShedder_StuckVectorShrinksLimit(t *testing.T) {
+	mockUser := new(UserService)
+	mockPermissions := new(PermissionsService)
+	mockVector := new(VectorMemoryService)
+
+	mockUser.On("GetUser", mock.Anything, "user123").Return(&pb_user.GetUserResponse{UserId: "user123"}, nil)
+	mockPermissions.On("CheckAccess", mock.Anything, "user123", "chat1").Return(&pb_permissions.CheckAccessResponse{Allowed: true}, nil)
+	mockVector.On("GetContext", mock.Anything, "chat1").Return(nil, context.DeadlineExceeded).Run(func(args mock.Arguments) {
+		<-args.Get(0).(context.Context).Done()
+	})
+
+	sla := 50 * time.Millisecond
+	summary := handler.NewChatSummaryHandler(mockUser, mockVector, mockPermissions, sla, handler.WithBudgets(handler.Budgets{
+		EncodingReserve: 10 * time.Millisecond,
+	}))
+	limiter := loadshed.New("summary", loadshed.Settings{Target: sla, InitialLimit: 10, Backoff: 0.9})
+	h := limiter.Middleware(summary)
+
+	for range 5 {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/chat/summary?user_id=user123&chat_id=chat1", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "true", w.Header().Get("X-Degraded"))
+	}
+
+	assert.Equal(t, 5, limiter.Limit())
+}
+
+func TestServeHTTP_BehindLoadShedder_FastFailuresKeepLimit(t *testing.T) {
+	mockUser := new(UserService)
+	mockPermissions := new(PermissionsService)
+	mockVector := new(VectorMemoryService)
+
+	mockUser.On("GetUser", mock.Anything, "user123").Return(&pb_user.GetUserResponse{UserId: "user123"}, nil)
+	mockUser.On("GetUser", mock.Anything, "missing").Return(nil, status.Error(codes.NotFound, "no such user"))
+	mockPermissions.On("CheckAccess", mock.Anything, mock.Anything, "chat1").Return(&pb_permissions.CheckAccessResponse{Allowed: true}, nil)
+	mockVector.On("GetContext", mock.Anything, "chat1").Return(nil, breaker.ErrOpen)
+
+	summary := handler.NewChatSummaryHandler(mockUser, mockVector, mockPermissions, 50*time.Millisecond)
+	limiter := loadshed.New("summary", loadshed.Settings{Target: 50 * time.Millisecond, InitialLimit: 10, Backoff: 0.9})
+	h := limiter.Middleware(summary)
+
+	for _, userID := range []string{"user123", "missing", "user123", "missing"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/v1/chat/summary?user_id="+userID+"&chat_id=chat1", nil))
+	}
+
+	assert.Equal(t, 10, limiter.Limit(), "an open breaker or a user error is not load")
+}
@@ -0,0 +1,96 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/vwency/resilient-scatter-gather/internal/handler"
+	"github.com/vwency/resilient-scatter-gather/internal/logging"
+	pb_permissions "github.com/vwency/resilient-scatter-gather/proto/permissions"
+	pb_user "github.com/vwency/resilient-scatter-gather/proto/user"
+)
+
+func decodeLogRecords(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestServeHTTP_WithLogger_LogsCorrelationFields(t *testing.T) {
+	mockUser := new(UserService)
+	mockPermissions := new(PermissionsService)
+	mockVector := new(VectorMemoryService)
+
+	mockUser.On("GetUser", mock.Anything, "user123").Return(&pb_user.GetUserResponse{UserId: "user123"}, nil)
+	mockPermissions.On("CheckAccess", mock.Anything, "user123", "chat1").Return(&pb_permissions.CheckAccessResponse{Allowed: true}, nil)
+	mockVector.On("GetContext", mock.Anything, "chat1").Return(nil, errors.New("vector down"))
+
+	var buf bytes.Buffer
+	h := handler.NewChatSummaryHandler(mockUser, mockVector, mockPermissions, 200*time.Millisecond,
+		handler.WithLogger(logging.New(&buf, logging.FormatJSON, slog.LevelInfo)))
+
+	req := httptest.NewRequest("GET", "/api/v1/chat/summary?user_id=user123&chat_id=chat1", nil)
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	records := decodeLogRecords(t, &buf)
+	require.Len(t, records, 2)
+
+	degraded := records[0]
+	assert.Equal(t, "WARN", degraded["level"])
+	assert.Equal(t, "vector", degraded["leg"])
+	assert.Equal(t, "vector down", degraded["error"])
+	assert.Equal(t, "user123", degraded["user_id"])
+	assert.Equal(t, "chat1", degraded["chat_id"])
+
+	completed := records[1]
+	assert.Equal(t, "request completed", completed["msg"])
+	assert.Equal(t, float64(http.StatusOK), completed["status"])
+	assert.Equal(t, true, completed["degraded"])
+	assert.Equal(t, "user123", completed["user_id"])
+	assert.Equal(t, "chat1", completed["chat_id"])
+}
+
+func TestServeHTTP_WithDebugLogger_LogsSucceededLegs(t *testing.T) {
+	mockUser := new(UserService)
+	mockPermissions := new(PermissionsService)
+	mockVector := new(VectorMemoryService)
+
+	mockUser.On("GetUser", mock.Anything, "user123").Return(&pb_user.GetUserResponse{UserId: "user123"}, nil)
+	mockPermissions.On("CheckAccess", mock.Anything, "user123", "chat1").Return(&pb_permissions.CheckAccessResponse{Allowed: true}, nil)
+	mockVector.On("GetContext", mock.Anything, "chat1").Return(nil, errors.New("vector down"))
+
+	var buf bytes.Buffer
+	h := handler.NewChatSummaryHandler(mockUser, mockVector, mockPermissions, 200*time.Millisecond,
+		handler.WithLogger(logging.New(&buf, logging.FormatJSON, slog.LevelDebug)))
+
+	req := httptest.NewRequest("GET", "/api/v1/chat/summary?user_id=user123&chat_id=chat1", nil)
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	var legs []any
+	for _, record := range decodeLogRecords(t, &buf) {
+		if record["msg"] == "leg succeeded" {
+			legs = append(legs, record["leg"])
+		}
+	}
+	assert.ElementsMatch(t, []any{"user", "permissions"}, legs)
+}
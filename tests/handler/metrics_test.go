@@ -0,0 +1,133 @@
+package handler_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/vwency/resilient-scatter-gather/internal/handler"
+	"github.com/vwency/resilient-scatter-gather/internal/metrics"
+	"github.com/vwency/resilient-scatter-gather/internal/services"
+	pb_permissions "github.com/vwency/resilient-scatter-gather/proto/permissions"
+	pb_user "github.com/vwency/resilient-scatter-gather/proto/user"
+)
+
+// metricValue returns the value of the counter, gauge or histogram sample
+// count named name whose labels include labels.
+func metricValue(t *testing.T, reg *prometheus.Registry, name string, labels map[string]string) float64 {
+	t.Helper()
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			if !hasLabels(m, labels) {
+				continue
+			}
+			switch {
+			case m.Counter != nil:
+				return m.Counter.GetValue()
+			case m.Gauge != nil:
+				return m.Gauge.GetValue()
+			case m.Histogram != nil:
+				return float64(m.Histogram.GetSampleCount())
+			}
+		}
+	}
+	return 0
+}
+
+func hasLabels(m *dto.Metric, labels map[string]string) bool {
+	for name, value := range labels {
+		found := false
+		for _, pair := range m.GetLabel() {
+			if pair.GetName() == name && pair.GetValue() == value {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func TestServeHTTP_WithMetrics_RecordsRequestAndLegMetrics(t *testing.T) {
+	mockUser := new(UserService)
+	mockPermissions := new(PermissionsService)
+	mockVector := new(VectorMemoryService)
+
+	userResp := &pb_user.GetUserResponse{UserId: "user123"}
+	mockUser.On("GetUser", mock.Anything, "user123").Return(userResp, nil)
+
+	permResp := &pb_permissions.CheckAccessResponse{Allowed: true}
+	mockPermissions.On("CheckAccess", mock.Anything, "user123", "chat1").Return(permResp, nil)
+
+	mockVector.On("GetContext", mock.Anything, "chat1").Return(nil, errors.New("vector down"))
+
+	reg := prometheus.NewRegistry()
+	m := metrics.New(reg)
+
+	h := handler.NewChatSummaryHandler(
+		services.NewUserServiceMetrics(mockUser, m),
+		services.NewVectorMemoryServiceMetrics(mockVector, m),
+		services.NewPermissionsServiceMetrics(mockPermissions, m),
+		200*time.Millisecond,
+		handler.WithMetrics(m),
+	)
+
+	req := httptest.NewRequest("GET", "/api/chat-summary?user_id=user123&chat_id=chat1", nil)
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1.0, metricValue(t, reg, "gateway_http_requests_total", map[string]string{"endpoint": "summary", "status": "200"}))
+	assert.Equal(t, 1.0, metricValue(t, reg, "gateway_http_request_duration_seconds", map[string]string{"endpoint": "summary", "status": "200"}))
+	assert.Equal(t, 0.0, metricValue(t, reg, "gateway_http_requests_in_flight", nil))
+	assert.Equal(t, 1.0, metricValue(t, reg, "gateway_degraded_responses_total", map[string]string{"leg": "vector"}))
+	assert.Equal(t, 1.0, metricValue(t, reg, "gateway_backend_call_duration_seconds", map[string]string{"service": "UserService"}))
+	assert.Equal(t, 1.0, metricValue(t, reg, "gateway_backend_call_errors_total", map[string]string{"service": "VectorMemoryService", "code": "Unknown"}))
+	assert.Equal(t, 0.0, metricValue(t, reg, "gateway_sla_breaches_total", map[string]string{"endpoint": "summary"}))
+}
+
+func TestServeHTTP_WithMetrics_CountsSLABreachesAndErrors(t *testing.T) {
+	mockUser := new(UserService)
+	mockPermissions := new(PermissionsService)
+	mockVector := new(VectorMemoryService)
+
+	mockUser.On("GetUser", mock.Anything, "user123").Return(nil, errors.New("user service down")).Run(func(args mock.Arguments) {
+		time.Sleep(300 * time.Millisecond)
+	})
+
+	permResp := &pb_permissions.CheckAccessResponse{Allowed: true}
+	mockPermissions.On("CheckAccess", mock.Anything, "user123", "chat1").Return(permResp, nil)
+
+	mockVector.On("GetContext", mock.Anything, "chat1").Return(nil, errors.New("vector down"))
+
+	reg := prometheus.NewRegistry()
+	m := metrics.New(reg)
+
+	h := handler.NewChatSummaryHandler(mockUser, mockVector, mockPermissions, 50*time.Millisecond, handler.WithMetrics(m))
+
+	req := httptest.NewRequest("GET", "/api/chat-summary?user_id=user123&chat_id=chat1", nil)
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, 1.0, metricValue(t, reg, "gateway_http_requests_total", map[string]string{"endpoint": "summary", "status": "500"}))
+	assert.Equal(t, 1.0, metricValue(t, reg, "gateway_sla_breaches_total", map[string]string{"endpoint": "summary"}))
+	assert.Equal(t, 0.0, metricValue(t, reg, "gateway_degraded_responses_total", map[string]string{"leg": "vector"}))
+}
@@ -0,0 +1,127 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/vwency/resilient-scatter-gather/internal/handler"
+	"github.com/vwency/resilient-scatter-gather/internal/models"
+	pb_permissions "github.com/vwency/resilient-scatter-gather/proto/permissions"
+	pb_user "github.com/vwency/resilient-scatter-gather/proto/user"
+	pb_vector "github.com/vwency/resilient-scatter-gather/proto/vector"
+)
+
+func TestServeHTTP_PermissionsDenied_ReturnsForbiddenWithReason(t *testing.T) {
+	mockUser := new(UserService)
+	mockPermissions := new(PermissionsService)
+	mockVector := new(VectorMemoryService)
+
+	userResp := &pb_user.GetUserResponse{
+		UserId:   "user123",
+		Username: "secret-username",
+		Email:    "secret@example.com",
+	}
+	mockUser.On("GetUser", mock.Anything, "user123").Return(userResp, nil).Maybe()
+
+	permResp := &pb_permissions.CheckAccessResponse{
+		Allowed: false,
+		Reason:  "User is not a member of the chat",
+	}
+	mockPermissions.On("CheckAccess", mock.Anything, "user123", "chat1").Return(permResp, nil)
+
+	vectorResp := &pb_vector.GetContextResponse{
+		Items:      []*pb_vector.ContextItem{{Content: "secret context"}},
+		TotalCount: 1,
+	}
+	mockVector.On("GetContext", mock.Anything, "chat1").Return(vectorResp, nil).Maybe()
+
+	h := handler.NewChatSummaryHandler(mockUser, mockVector, mockPermissions, 200*time.Millisecond)
+
+	req := httptest.NewRequest("GET", "/api/chat-summary?user_id=user123&chat_id=chat1", nil)
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	body := w.Body.String()
+	assert.NotContains(t, body, "secret context")
+	assert.NotContains(t, body, "secret-username")
+	assert.NotContains(t, body, "secret@example.com")
+
+	var errResponse models.ErrorResponse
+	err := json.Unmarshal([]byte(body), &errResponse)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, errResponse.Code)
+	assert.Equal(t, "User is not a member of the chat", errResponse.Message)
+
+	mockPermissions.AssertExpectations(t)
+}
+
+func TestServeHTTP_PermissionsDeniedWithoutReason_ReturnsForbidden(t *testing.T) {
+	mockUser := new(UserService)
+	mockPermissions := new(PermissionsService)
+	mockVector := new(VectorMemoryService)
+
+	userResp := &pb_user.GetUserResponse{UserId: "user123"}
+	mockUser.On("GetUser", mock.Anything, "user123").Return(userResp, nil).Maybe()
+
+	permResp := &pb_permissions.CheckAccessResponse{Allowed: false}
+	mockPermissions.On("CheckAccess", mock.Anything, "user123", "chat1").Return(permResp, nil)
+
+	vectorResp := &pb_vector.GetContextResponse{Items: []*pb_vector.ContextItem{}}
+	mockVector.On("GetContext", mock.Anything, "chat1").Return(vectorResp, nil).Maybe()
+
+	h := handler.NewChatSummaryHandler(mockUser, mockVector, mockPermissions, 200*time.Millisecond)
+
+	req := httptest.NewRequest("GET", "/api/chat-summary?user_id=user123&chat_id=chat1", nil)
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	var errResponse models.ErrorResponse
+	err := json.NewDecoder(w.Body).Decode(&errResponse)
+	assert.NoError(t, err)
+	assert.Equal(t, "access denied", errResponse.Message)
+}
+
+func TestServeHTTP_PermissionsDeniedWhileVectorSlow_ReturnsForbiddenWithoutWaiting(t *testing.T) {
+	mockUser := new(UserService)
+	mockPermissions := new(PermissionsService)
+	mockVector := new(VectorMemoryService)
+
+	userResp := &pb_user.GetUserResponse{UserId: "user123"}
+	mockUser.On("GetUser", mock.Anything, "user123").Return(userResp, nil).Maybe()
+
+	permResp := &pb_permissions.CheckAccessResponse{Allowed: false, Reason: "revoked"}
+	mockPermissions.On("CheckAccess", mock.Anything, "user123", "chat1").Return(permResp, nil).Run(func(args mock.Arguments) {
+		time.Sleep(10 * time.Millisecond)
+	})
+
+	vectorResp := &pb_vector.GetContextResponse{
+		Items: []*pb_vector.ContextItem{{Content: "secret context"}},
+	}
+	mockVector.On("GetContext", mock.Anything, "chat1").Return(vectorResp, nil).Run(func(args mock.Arguments) {
+		time.Sleep(150 * time.Millisecond)
+	}).Maybe()
+
+	h := handler.NewChatSummaryHandler(mockUser, mockVector, mockPermissions, 200*time.Millisecond)
+
+	req := httptest.NewRequest("GET", "/api/chat-summary?user_id=user123&chat_id=chat1", nil)
+	w := httptest.NewRecorder()
+
+	start := time.Now()
+	h.ServeHTTP(w, req)
+	elapsed := time.Since(start)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Less(t, elapsed, 100*time.Millisecond)
+	assert.NotContains(t, w.Body.String(), "secret context")
+}
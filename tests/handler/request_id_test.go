@@ -0,0 +1,88 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/vwency/resilient-scatter-gather/internal/handler"
+	"github.com/vwency/resilient-scatter-gather/internal/models"
+	"github.com/vwency/resilient-scatter-gather/internal/requestid"
+	pb_permissions "github.com/vwency/resilient-scatter-gather/proto/permissions"
+	pb_user "github.com/vwency/resilient-scatter-gather/proto/user"
+	pb_vector "github.com/vwency/resilient-scatter-gather/proto/vector"
+)
+
+func TestServeHTTP_RequestIDHeader_IsPropagatedAndEchoed(t *testing.T) {
+	mockUser := new(UserService)
+	mockPermissions := new(PermissionsService)
+	mockVector := new(VectorMemoryService)
+
+	var seen string
+	mockUser.On("GetUser", mock.Anything, "user123").Return(&pb_user.GetUserResponse{UserId: "user123"}, nil).Run(func(args mock.Arguments) {
+		seen, _ = requestid.FromContext(args.Get(0).(context.Context))
+	})
+	mockPermissions.On("CheckAccess", mock.Anything, "user123", "chat1").Return(&pb_permissions.CheckAccessResponse{Allowed: true}, nil)
+	mockVector.On("GetContext", mock.Anything, "chat1").Return(&pb_vector.GetContextResponse{}, nil)
+
+	h := handler.NewChatSummaryHandler(mockUser, mockVector, mockPermissions, 200*time.Millisecond)
+
+	req := httptest.NewRequest("GET", "/api/v1/chat/summary?user_id=user123&chat_id=chat1", nil)
+	req.Header.Set("X-Request-ID", "req-abc")
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "req-abc", w.Header().Get("X-Request-ID"))
+	assert.Equal(t, "req-abc", seen)
+}
+
+func TestServeHTTP_NoRequestID_GeneratesOne(t *testing.T) {
+	h := handler.NewChatSummaryHandler(new(UserService), new(VectorMemoryService), new(PermissionsService), 200*time.Millisecond)
+
+	req := httptest.NewRequest("GET", "/api/v1/chat/summary", nil)
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	requestID := w.Header().Get("X-Request-ID")
+	assert.Len(t, requestID, 32)
+
+	var errResp models.ErrorResponse
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&errResp))
+	assert.Equal(t, requestID, errResp.RequestID)
+}
+
+func TestServeHTTP_CriticalFailure_ErrorResponseCarriesRequestID(t *testing.T) {
+	mockUser := new(UserService)
+	mockPermissions := new(PermissionsService)
+	mockVector := new(VectorMemoryService)
+
+	mockUser.On("GetUser", mock.Anything, "user123").Return(nil, errors.New("user service down"))
+	mockPermissions.On("CheckAccess", mock.Anything, "user123", "chat1").Return(&pb_permissions.CheckAccessResponse{Allowed: true}, nil).Maybe()
+	mockVector.On("GetContext", mock.Anything, "chat1").Return(&pb_vector.GetContextResponse{}, nil).Maybe()
+
+	h := handler.NewChatSummaryHandler(mockUser, mockVector, mockPermissions, 200*time.Millisecond)
+
+	req := httptest.NewRequest("GET", "/api/v1/chat/summary?user_id=user123&chat_id=chat1", nil)
+	req.Header.Set("X-Request-ID", "req-500")
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "req-500", w.Header().Get("X-Request-ID"))
+
+	var errResp models.ErrorResponse
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&errResp))
+	assert.Equal(t, "req-500", errResp.RequestID)
+}
@@ -0,0 +1,118 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/vwency/resilient-scatter-gather/internal/handler"
+	pb_permissions "github.com/vwency/resilient-scatter-gather/proto/permissions"
+	pb_user "github.com/vwency/resilient-scatter-gather/proto/user"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newTestTracerProvider(t *testing.T) (*sdktrace.TracerProvider, *tracetest.InMemoryExporter) {
+	t.Helper()
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+
+	return tp, exporter
+}
+
+func spanByName(spans tracetest.SpanStubs, name string) (tracetest.SpanStub, bool) {
+	for _, span := range spans {
+		if span.Name == name {
+			return span, true
+		}
+	}
+	return tracetest.SpanStub{}, false
+}
+
+func TestServeHTTP_WithTracerProvider_RecordsRequestAndLegSpans(t *testing.T) {
+	mockUser := new(UserService)
+	mockPermissions := new(PermissionsService)
+	mockVector := new(VectorMemoryService)
+
+	var userSpan trace.SpanContext
+	userResp := &pb_user.GetUserResponse{UserId: "user123"}
+	mockUser.On("GetUser", mock.Anything, "user123").Return(userResp, nil).Run(func(args mock.Arguments) {
+		userSpan = trace.SpanContextFromContext(args.Get(0).(context.Context))
+	})
+
+	permResp := &pb_permissions.CheckAccessResponse{Allowed: true}
+	mockPermissions.On("CheckAccess", mock.Anything, "user123", "chat1").Return(permResp, nil)
+
+	mockVector.On("GetContext", mock.Anything, "chat1").Return(nil, context.DeadlineExceeded)
+
+	tp, exporter := newTestTracerProvider(t)
+	h := handler.NewChatSummaryHandler(mockUser, mockVector, mockPermissions, 200*time.Millisecond,
+		handler.WithTracerProvider(tp))
+
+	req := httptest.NewRequest("GET", "/api/v1/chat/summary?user_id=user123&chat_id=chat1", nil)
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 4)
+
+	root, ok := spanByName(spans, "GET /api/v1/chat/summary")
+	require.True(t, ok)
+	assert.Equal(t, trace.SpanKindServer, root.SpanKind)
+
+	for _, leg := range []string{"user", "permissions", "vector"} {
+		span, ok := spanByName(spans, "scatter.leg "+leg)
+		require.True(t, ok, leg)
+		assert.Equal(t, root.SpanContext.SpanID(), span.Parent.SpanID(), leg)
+		assert.Equal(t, root.SpanContext.TraceID(), span.SpanContext.TraceID(), leg)
+	}
+
+	vectorSpan, _ := spanByName(spans, "scatter.leg vector")
+	assert.Contains(t, vectorSpan.Attributes, attribute.String("scatter.status", "timeout"))
+
+	legSpan, _ := spanByName(spans, "scatter.leg user")
+	assert.Equal(t, legSpan.SpanContext.SpanID(), userSpan.SpanID())
+}
+
+func TestServeHTTP_IncomingTraceparent_ContinuesTrace(t *testing.T) {
+	mockUser := new(UserService)
+	mockPermissions := new(PermissionsService)
+	mockVector := new(VectorMemoryService)
+
+	mockUser.On("GetUser", mock.Anything, "user123").Return(&pb_user.GetUserResponse{UserId: "user123"}, nil)
+	mockPermissions.On("CheckAccess", mock.Anything, "user123", "chat1").Return(&pb_permissions.CheckAccessResponse{Allowed: true}, nil)
+	mockVector.On("GetContext", mock.Anything, "chat1").Return(nil, context.DeadlineExceeded)
+
+	previous := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(previous) })
+
+	tp, exporter := newTestTracerProvider(t)
+	h := handler.NewChatSummaryHandler(mockUser, mockVector, mockPermissions, 200*time.Millisecond,
+		handler.WithTracerProvider(tp))
+
+	req := httptest.NewRequest("GET", "/api/v1/chat/summary?user_id=user123&chat_id=chat1", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, req)
+
+	root, ok := spanByName(exporter.GetSpans(), "GET /api/v1/chat/summary")
+	require.True(t, ok)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", root.SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", root.Parent.SpanID().String())
+}
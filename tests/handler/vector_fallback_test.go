@@ -0,0 +1,104 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/vwency/resilient-scatter-gather/internal/handler"
+	"github.com/vwency/resilient-scatter-gather/internal/models"
+	pb_permissions "github.com/vwency/resilient-scatter-gather/proto/permissions"
+	pb_user "github.com/vwency/resilient-scatter-gather/proto/user"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newFallbackMocks() (*UserService, *PermissionsService, *VectorMemoryService) {
+	mockUser := new(UserService)
+	mockPermissions := new(PermissionsService)
+	mockVector := new(VectorMemoryService)
+
+	userResp := &pb_user.GetUserResponse{UserId: "user123"}
+	mockUser.On("GetUser", mock.Anything, "user123").Return(userResp, nil)
+
+	permResp := &pb_permissions.CheckAccessResponse{Allowed: true}
+	mockPermissions.On("CheckAccess", mock.Anything, "user123", "chat1").Return(permResp, nil)
+
+	mockVector.On("GetContext", mock.Anything, "chat1").Return(nil, status.Error(codes.Unavailable, "vector down"))
+
+	return mockUser, mockPermissions, mockVector
+}
+
+func TestServeHTTP_VectorFallbackEmpty_ReturnsEmptyContext(t *testing.T) {
+	mockUser, mockPermissions, mockVector := newFallbackMocks()
+
+	h := handler.NewChatSummaryHandler(mockUser, mockVector, mockPermissions, 200*time.Millisecond,
+		handler.WithVectorFallback(handler.VectorFallbackEmpty))
+
+	req := httptest.NewRequest("GET", "/api/chat-summary?user_id=user123&chat_id=chat1", nil)
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.ChatSummaryResponse
+	err := json.NewDecoder(w.Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.NotNil(t, response.Context)
+	assert.Empty(t, response.Context.Items)
+	assert.True(t, response.Degraded)
+	assert.Equal(t, "error", response.Legs["vector"].Status)
+}
+
+func TestServeHTTP_VectorFallbackOmit_OmitsContext(t *testing.T) {
+	mockUser, mockPermissions, mockVector := newFallbackMocks()
+
+	h := handler.NewChatSummaryHandler(mockUser, mockVector, mockPermissions, 200*time.Millisecond,
+		handler.WithVectorFallback(handler.VectorFallbackOmit))
+
+	req := httptest.NewRequest("GET", "/api/chat-summary?user_id=user123&chat_id=chat1", nil)
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.ChatSummaryResponse
+	err := json.NewDecoder(w.Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.Nil(t, response.Context)
+	assert.True(t, response.Degraded)
+}
+
+func TestServeHTTP_VectorFallbackFail_ReturnsInternalServerError(t *testing.T) {
+	mockUser, mockPermissions, mockVector := newFallbackMocks()
+
+	h := handler.NewChatSummaryHandler(mockUser, mockVector, mockPermissions, 200*time.Millisecond,
+		handler.WithVectorFallback(handler.VectorFallbackFail))
+
+	req := httptest.NewRequest("GET", "/api/chat-summary?user_id=user123&chat_id=chat1", nil)
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	var errResponse models.ErrorResponse
+	err := json.NewDecoder(w.Body).Decode(&errResponse)
+	assert.NoError(t, err)
+	assert.Contains(t, errResponse.Message, "vector")
+}
+
+func TestParseVectorFallback_UnknownValue_ReturnsError(t *testing.T) {
+	fallback, err := handler.ParseVectorFallback("")
+	assert.NoError(t, err)
+	assert.Equal(t, handler.VectorFallbackOmit, fallback)
+
+	_, err = handler.ParseVectorFallback("retry-forever")
+	assert.Error(t, err)
+}
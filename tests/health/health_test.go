@@ -0,0 +1,209 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vwency/resilient-scatter-gather/internal/health"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+type fakeConn struct {
+	state     connectivity.State
+	connected bool
+}
+
+func (c *fakeConn) GetState() connectivity.State {
+	return c.state
+}
+
+func (c *fakeConn) Connect() {
+	c.connected = true
+}
+
+func decodeReport(t *testing.T, w *httptest.ResponseRecorder) health.Report {
+	t.Helper()
+
+	var report health.Report
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&report))
+	return report
+}
+
+func TestLivenessHandler_AlwaysOK(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	health.LivenessHandler().ServeHTTP(w, httptest.NewRequest("GET", "/livez", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, health.StatusAlive, decodeReport(t, w).Status)
+}
+
+func TestLegacyHandler_KeepsHealthyContract(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	health.LegacyHandler().ServeHTTP(w, httptest.NewRequest("GET", "/health", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var body map[string]string
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+	assert.Equal(t, "healthy", body["status"])
+	_, err := time.Parse(time.RFC3339, body["timestamp"])
+	assert.NoError(t, err)
+}
+
+func TestReadinessHandler_AllConnectionsReady_ReturnsOK(t *testing.T) {
+	checker := health.NewChecker(50*time.Millisecond,
+		health.Dependency{Name: "UserService", Critical: true, Conn: &fakeConn{state: connectivity.Ready}},
+		health.Dependency{Name: "VectorMemoryService", Critical: false, Conn: &fakeConn{state: connectivity.Ready}},
+	)
+	w := httptest.NewRecorder()
+
+	health.ReadinessHandler(checker).ServeHTTP(w, httptest.NewRequest("GET", "/readyz", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	report := decodeReport(t, w)
+	assert.Equal(t, health.StatusReady, report.Status)
+	require.Len(t, report.Dependencies, 2)
+	assert.Equal(t, health.DependencyStatus{Name: "UserService", Status: health.StatusUp, Critical: true, State: "READY"}, report.Dependencies[0])
+}
+
+func TestReadinessHandler_CriticalDependencyDown_Returns503(t *testing.T) {
+	checker := health.NewChecker(50*time.Millisecond,
+		health.Dependency{Name: "UserService", Critical: true, Conn: &fakeConn{state: connectivity.TransientFailure}},
+		health.Dependency{Name: "VectorMemoryService", Critical: false, Conn: &fakeConn{state: connectivity.Ready}},
+	)
+	w := httptest.NewRecorder()
+
+	health.ReadinessHandler(checker).ServeHTTP(w, httptest.NewRequest("GET", "/readyz", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	report := decodeReport(t, w)
+	assert.Equal(t, health.StatusNotReady, report.Status)
+	assert.Equal(t, health.StatusDown, report.Dependencies[0].Status)
+	assert.Equal(t, "TRANSIENT_FAILURE", report.Dependencies[0].State)
+}
+
+func TestCheck_OptionalDependencyDown_StaysReady(t *testing.T) {
+	checker := health.NewChecker(50*time.Millisecond,
+		health.Dependency{Name: "UserService", Critical: true, Conn: &fakeConn{state: connectivity.Ready}},
+		health.Dependency{Name: "VectorMemoryService", Critical: false, Conn: &fakeConn{state: connectivity.TransientFailure}},
+	)
+
+	report := checker.Check(context.Background())
+
+	assert.Equal(t, health.StatusReady, report.Status)
+	assert.Equal(t, health.StatusDown, report.Dependencies[1].Status)
+	assert.False(t, report.Dependencies[1].Critical)
+}
+
+func TestCheck_IdleConnection_IsKickedAndReportedDown(t *testing.T) {
+	conn := &fakeConn{state: connectivity.Idle}
+	checker := health.NewChecker(50*time.Millisecond,
+		health.Dependency{Name: "UserService", Critical: true, Conn: conn},
+	)
+
+	report := checker.Check(context.Background())
+
+	assert.True(t, conn.connected)
+	assert.Equal(t, health.StatusNotReady, report.Status)
+}
+
+func startHealthServer(t *testing.T) (*grpchealth.Server, *grpc.ClientConn) {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer()
+	healthServer := grpchealth.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return healthServer, conn
+}
+
+func TestCheck_GRPCHealthProtocol_ReflectsServingStatus(t *testing.T) {
+	healthServer, conn := startHealthServer(t)
+	checker := health.NewChecker(time.Second, health.Dependency{
+		Name:     "UserService",
+		Critical: true,
+		Conn:     conn,
+		Health:   healthpb.NewHealthClient(conn),
+		Service:  "user.UserService",
+	})
+
+	healthServer.SetServingStatus("user.UserService", healthpb.HealthCheckResponse_SERVING)
+	report := checker.Check(context.Background())
+	assert.Equal(t, health.StatusReady, report.Status)
+	assert.Equal(t, health.StatusUp, report.Dependencies[0].Status)
+	assert.Equal(t, "READY", report.Dependencies[0].State)
+
+	healthServer.SetServingStatus("user.UserService", healthpb.HealthCheckResponse_NOT_SERVING)
+	report = checker.Check(context.Background())
+	assert.Equal(t, health.StatusNotReady, report.Status)
+	assert.Equal(t, "NOT_SERVING", report.Dependencies[0].Error)
+}
+
+func TestCheck_GRPCHealthProtocol_UnreachableBackend_IsDown(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := lis.Addr().String()
+	require.NoError(t, lis.Close())
+
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	checker := health.NewChecker(200*time.Millisecond, health.Dependency{
+		Name:     "PermissionsService",
+		Critical: true,
+		Conn:     conn,
+		Health:   healthpb.NewHealthClient(conn),
+	})
+
+	report := checker.Check(context.Background())
+
+	assert.Equal(t, health.StatusNotReady, report.Status)
+	assert.NotEmpty(t, report.Dependencies[0].Error)
+}
+
+func TestCheck_GRPCHealthProtocolUnimplemented_FallsBackToConnectionState(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	checker := health.NewChecker(time.Second, health.Dependency{
+		Name:     "VectorMemoryService",
+		Critical: true,
+		Conn:     conn,
+		Health:   healthpb.NewHealthClient(conn),
+	})
+
+	report := checker.Check(context.Background())
+
+	assert.Equal(t, health.StatusReady, report.Status)
+	assert.Equal(t, health.StatusUp, report.Dependencies[0].Status)
+	assert.Empty(t, report.Dependencies[0].Error)
+}
@@ -0,0 +1,177 @@
+package hedge_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vwency/resilient-scatter-gather/internal/hedge"
+)
+
+func newTestHedger(hedgesPerSecond float64) *hedge.Hedger {
+	return hedge.New("test", hedge.Settings{
+		Percentile:         0.9,
+		InitialDelay:       20 * time.Millisecond,
+		MinSamples:         5,
+		SampleSize:         10,
+		MaxHedgesPerSecond: hedgesPerSecond,
+	})
+}
+
+func TestDo_FastPrimary_DoesNotHedge(t *testing.T) {
+	h := newTestHedger(10)
+	var calls atomic.Int32
+
+	value, err := hedge.Do(context.Background(), h, func(ctx context.Context) (string, error) {
+		calls.Add(1)
+		return "primary", nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "primary", value)
+
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestDo_SlowPrimary_HedgeWinsAndPrimaryIsCancelled(t *testing.T) {
+	h := newTestHedger(10)
+	var calls atomic.Int32
+	primaryErr := make(chan error, 1)
+
+	start := time.Now()
+	value, err := hedge.Do(context.Background(), h, func(ctx context.Context) (string, error) {
+		if calls.Add(1) == 1 {
+			select {
+			case <-ctx.Done():
+				primaryErr <- ctx.Err()
+				return "", ctx.Err()
+			case <-time.After(time.Second):
+				primaryErr <- nil
+				return "primary", nil
+			}
+		}
+		return "hedge", nil
+	})
+	elapsed := time.Since(start)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "hedge", value)
+	assert.Less(t, elapsed, 100*time.Millisecond)
+	assert.ErrorIs(t, <-primaryErr, context.Canceled)
+}
+
+func TestDo_HedgeBudgetExhausted_WaitsForPrimary(t *testing.T) {
+	h := newTestHedger(1)
+	var calls atomic.Int32
+
+	slow := func(ctx context.Context) (string, error) {
+		calls.Add(1)
+		time.Sleep(40 * time.Millisecond)
+		return "ok", nil
+	}
+
+	_, err := hedge.Do(context.Background(), h, slow)
+	assert.NoError(t, err)
+	_, err = hedge.Do(context.Background(), h, slow)
+	assert.NoError(t, err)
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestDo_DeadlineCloserThanDelay_SkipsHedgeAndKeepsToken(t *testing.T) {
+	h := newTestHedger(1)
+	var calls atomic.Int32
+
+	slow := func(ctx context.Context) (string, error) {
+		calls.Add(1)
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(40 * time.Millisecond):
+			return "ok", nil
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	_, err := hedge.Do(ctx, h, slow)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, int32(1), calls.Load())
+
+	// The only token was not spent on the skipped hedge.
+	_, err = hedge.Do(context.Background(), h, slow)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestDo_BothAttemptsFail_ReturnsError(t *testing.T) {
+	h := newTestHedger(10)
+
+	_, err := hedge.Do(context.Background(), h, func(ctx context.Context) (string, error) {
+		time.Sleep(30 * time.Millisecond)
+		return "", errors.New("backend down")
+	})
+
+	assert.EqualError(t, err, "backend down")
+}
+
+func TestDelay_AfterMinSamples_UsesObservedPercentile(t *testing.T) {
+	h := newTestHedger(10)
+	assert.Equal(t, 20*time.Millisecond, h.Delay())
+
+	for i := 0; i < 10; i++ {
+		_, err := hedge.Do(context.Background(), h, func(ctx context.Context) (string, error) {
+			time.Sleep(2 * time.Millisecond)
+			return "ok", nil
+		})
+		assert.NoError(t, err)
+	}
+
+	delay := h.Delay()
+	assert.GreaterOrEqual(t, delay, 2*time.Millisecond)
+	assert.Less(t, delay, 20*time.Millisecond)
+}
+
+func TestDelay_HedgeWins_RecordsPrimaryLowerBound(t *testing.T) {
+	h := newTestHedger(100)
+
+	for range 6 {
+		var calls atomic.Int32
+		_, err := hedge.Do(context.Background(), h, func(ctx context.Context) (string, error) {
+			if calls.Add(1) == 1 {
+				<-ctx.Done()
+				return "", ctx.Err()
+			}
+			return "hedge", nil
+		})
+		assert.NoError(t, err)
+	}
+
+	assert.GreaterOrEqual(t, h.Delay(), 20*time.Millisecond, "fast hedges must not pull the delay down")
+}
+
+func TestDelay_OldSamplesLeaveTheWindow(t *testing.T) {
+	h := newTestHedger(0)
+	run := func(latency time.Duration) {
+		_, err := hedge.Do(context.Background(), h, func(ctx context.Context) (string, error) {
+			time.Sleep(latency)
+			return "ok", nil
+		})
+		assert.NoError(t, err)
+	}
+
+	for range 10 {
+		run(30 * time.Millisecond)
+	}
+	assert.GreaterOrEqual(t, h.Delay(), 30*time.Millisecond)
+
+	for range 10 {
+		run(time.Millisecond)
+	}
+	assert.Less(t, h.Delay(), 20*time.Millisecond)
+}
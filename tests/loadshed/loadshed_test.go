@@ -0,0 +1,199 @@
+package loadshed_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vwency/resilient-scatter-gather/internal/loadshed"
+	"github.com/vwency/resilient-scatter-gather/internal/metrics"
+	"github.com/vwency/resilient-scatter-gather/tests/testclock"
+)
+
+func acquire(t *testing.T, l *loadshed.Limiter, n int) []func(bool) {
+	t.Helper()
+
+	done := make([]func(bool), n)
+	for i := range done {
+		var ok bool
+		done[i], ok = l.Acquire()
+		require.True(t, ok, "request %d must be admitted", i)
+	}
+	return done
+}
+
+func metricValue(t *testing.T, reg *prometheus.Registry, name, endpoint string) float64 {
+	t.Helper()
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			for _, pair := range m.GetLabel() {
+				if pair.GetName() != "endpoint" || pair.GetValue() != endpoint {
+					continue
+				}
+				if m.Gauge != nil {
+					return m.Gauge.GetValue()
+				}
+				return m.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
+
+func TestLimiter_ShedsRequestsOverLimit(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	l := loadshed.New("summary", loadshed.Settings{Target: time.Second, InitialLimit: 2}, loadshed.WithMetrics(metrics.New(reg)))
+
+	done := acquire(t, l, 2)
+	_, ok := l.Acquire()
+	assert.False(t, ok)
+	assert.Equal(t, 1.0, metricValue(t, reg, "gateway_shed_requests_total", "summary"))
+	assert.Equal(t, 2.0, metricValue(t, reg, "gateway_concurrency_limit", "summary"))
+
+	done[0](false)
+	_, ok = l.Acquire()
+	assert.True(t, ok, "a completed request frees its slot")
+}
+
+func TestLimiter_SlowRequestsShrinkLimitOnce(t *testing.T) {
+	clock := testclock.New()
+	l := loadshed.NewWithClock("summary", loadshed.Settings{
+		Target:       100 * time.Millisecond,
+		InitialLimit: 10,
+		MinLimit:     2,
+		Backoff:      0.5,
+	}, clock.Now)
+
+	done := acquire(t, l, 4)
+	clock.Advance(150 * time.Millisecond)
+	for _, d := range done {
+		d(false)
+	}
+	assert.Equal(t, 5, l.Limit(), "requests in flight together back off once")
+
+	for range 3 {
+		done := acquire(t, l, 1)
+		clock.Advance(150 * time.Millisecond)
+		done[0](false)
+	}
+	assert.Equal(t, 2, l.Limit(), "the limit never drops below MinLimit")
+}
+
+func TestLimiter_FastRequestsGrowLimitOnlyWhenInUse(t *testing.T) {
+	clock := testclock.New()
+	l := loadshed.NewWithClock("summary", loadshed.Settings{
+		Target:       100 * time.Millisecond,
+		InitialLimit: 4,
+		MaxLimit:     5,
+	}, clock.Now)
+
+	for range 10 {
+		done := acquire(t, l, 1)
+		clock.Advance(10 * time.Millisecond)
+		done[0](false)
+	}
+	assert.Equal(t, 4, l.Limit(), "an idle limiter keeps its limit")
+
+	for range 3 {
+		for _, d := range acquire(t, l, l.Limit()) {
+			clock.Advance(10 * time.Millisecond)
+			d(false)
+		}
+	}
+	assert.Equal(t, 5, l.Limit(), "the limit never exceeds MaxLimit")
+}
+
+func TestLimiter_DroppedRequestsShrinkLimit(t *testing.T) {
+	clock := testclock.New()
+	l := loadshed.NewWithClock("summary", loadshed.Settings{
+		Target:       100 * time.Millisecond,
+		InitialLimit: 10,
+		Backoff:      0.5,
+	}, clock.Now)
+
+	done := acquire(t, l, 1)
+	clock.Advance(10 * time.Millisecond)
+	done[0](true)
+
+	assert.Equal(t, 5, l.Limit(), "a fast but dropped request still backs off")
+}
+
+func TestMiddleware_OnlyMarkedRequestsAreDrops(t *testing.T) {
+	tests := map[string]struct {
+		next http.HandlerFunc
+		want int
+	}{
+		"5xx": {
+			next: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+			want: 10,
+		},
+		"degraded": {
+			next: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Degraded", "true")
+				w.WriteHeader(http.StatusOK)
+			},
+			want: 10,
+		},
+		"missed deadline": {
+			next: func(w http.ResponseWriter, r *http.Request) {
+				loadshed.MarkDropped(r.Context())
+				w.WriteHeader(http.StatusOK)
+			},
+			want: 5,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			l := loadshed.New("summary", loadshed.Settings{Target: time.Second, InitialLimit: 10, Backoff: 0.5})
+
+			l.Middleware(tt.next).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/v1/chat/summary", nil))
+
+			assert.Equal(t, tt.want, l.Limit())
+		})
+	}
+}
+
+func TestMiddleware_RejectsWith503(t *testing.T) {
+	l := loadshed.New("summary", loadshed.Settings{Target: time.Second, InitialLimit: 1})
+
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	h := l.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(entered)
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	first := httptest.NewRecorder()
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		h.ServeHTTP(first, httptest.NewRequest("GET", "/api/v1/chat/summary", nil))
+	}()
+	<-entered
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/chat/summary", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), "overloaded")
+
+	close(release)
+	<-finished
+	assert.Equal(t, http.StatusOK, first.Code)
+	_, ok := l.Acquire()
+	assert.True(t, ok, "the middleware releases its slot")
+}
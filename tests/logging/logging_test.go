@@ -0,0 +1,94 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vwency/resilient-scatter-gather/internal/logging"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		in   string
+		want slog.Level
+	}{
+		{in: "", want: slog.LevelInfo},
+		{in: "debug", want: slog.LevelDebug},
+		{in: "INFO", want: slog.LevelInfo},
+		{in: "warning", want: slog.LevelWarn},
+		{in: "error", want: slog.LevelError},
+	}
+
+	for _, tt := range tests {
+		level, err := logging.ParseLevel(tt.in)
+		assert.NoError(t, err, tt.in)
+		assert.Equal(t, tt.want, level, tt.in)
+	}
+
+	_, err := logging.ParseLevel("verbose")
+	assert.Error(t, err)
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := logging.ParseFormat("")
+	assert.NoError(t, err)
+	assert.Equal(t, logging.FormatJSON, format)
+
+	format, err = logging.ParseFormat("text")
+	assert.NoError(t, err)
+	assert.Equal(t, logging.FormatText, format)
+
+	_, err = logging.ParseFormat("xml")
+	assert.Error(t, err)
+}
+
+func TestNew_FiltersBelowLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logging.New(&buf, logging.FormatJSON, slog.LevelWarn)
+
+	logger.Info("dropped")
+	logger.Warn("kept")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 1)
+	assert.Contains(t, lines[0], `"msg":"kept"`)
+}
+
+func TestNew_AddsContextAttrsAndTraceIDs(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logging.New(&buf, logging.FormatJSON, slog.LevelInfo)
+
+	tp := sdktrace.NewTracerProvider()
+	ctx, span := tp.Tracer("test").Start(context.Background(), "request")
+	defer span.End()
+
+	ctx = logging.WithAttrs(ctx, slog.String("user_id", "user123"))
+	ctx = logging.WithAttrs(ctx, slog.String("chat_id", "chat1"))
+	logger.InfoContext(ctx, "hello", "leg", "vector")
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "hello", record["msg"])
+	assert.Equal(t, "user123", record["user_id"])
+	assert.Equal(t, "chat1", record["chat_id"])
+	assert.Equal(t, "vector", record["leg"])
+	assert.Equal(t, span.SpanContext().TraceID().String(), record["trace_id"])
+	assert.Equal(t, span.SpanContext().SpanID().String(), record["span_id"])
+}
+
+func TestNew_TextFormat(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logging.New(&buf, logging.FormatText, slog.LevelInfo)
+
+	logger.InfoContext(logging.WithAttrs(context.Background(), slog.String("user_id", "user123")), "hello")
+
+	assert.Contains(t, buf.String(), "msg=hello")
+	assert.Contains(t, buf.String(), "user_id=user123")
+}
@@ -0,0 +1,251 @@
+package microbatch_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vwency/resilient-scatter-gather/internal/microbatch"
+)
+
+// recorder answers every key with "value-<key>" and remembers the batches
+// it was called with.
+type recorder struct {
+	mu      sync.Mutex
+	batches [][]string
+}
+
+func (r *recorder) fetch(ctx context.Context, keys []string) ([]string, []error, error) {
+	r.mu.Lock()
+	r.batches = append(r.batches, append([]string(nil), keys...))
+	r.mu.Unlock()
+
+	values := make([]string, len(keys))
+	for i, key := range keys {
+		values[i] = "value-" + key
+	}
+	return values, nil, nil
+}
+
+func (r *recorder) sizes() []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sizes := make([]int, len(r.batches))
+	for i, batch := range r.batches {
+		sizes[i] = len(batch)
+	}
+	return sizes
+}
+
+func doAll(b *microbatch.Batcher[string, string], keys ...string) []string {
+	values := make([]string, len(keys))
+	var wg sync.WaitGroup
+	for i, key := range keys {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			values[i], _ = b.Do(context.Background(), key)
+		}()
+	}
+	wg.Wait()
+	return values
+}
+
+func TestDo_ConcurrentCalls_AreMergedIntoOneBatch(t *testing.T) {
+	r := &recorder{}
+	b := microbatch.New(microbatch.Settings{Window: 50 * time.Millisecond}, r.fetch)
+
+	values := doAll(b, "a", "b", "c", "d")
+
+	assert.Equal(t, []string{"value-a", "value-b", "value-c", "value-d"}, values)
+	assert.Equal(t, []int{4}, r.sizes())
+}
+
+func TestDo_SameKey_SharesOneSlot(t *testing.T) {
+	r := &recorder{}
+	b := microbatch.New(microbatch.Settings{Window: 50 * time.Millisecond}, r.fetch)
+
+	values := doAll(b, "a", "a", "b", "a")
+
+	assert.Equal(t, []string{"value-a", "value-a", "value-b", "value-a"}, values)
+	assert.Equal(t, []int{2}, r.sizes())
+}
+
+func TestDo_MaxSize_FlushesBeforeWindow(t *testing.T) {
+	r := &recorder{}
+	b := microbatch.New(microbatch.Settings{Window: time.Minute, MaxSize: 2}, r.fetch)
+
+	start := time.Now()
+	doAll(b, "a", "b", "c", "d")
+
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, []int{2, 2}, r.sizes())
+}
+
+func TestDo_CallsAfterWindow_StartNewBatch(t *testing.T) {
+	r := &recorder{}
+	b := microbatch.New(microbatch.Settings{Window: time.Millisecond}, r.fetch)
+
+	_, err := b.Do(context.Background(), "a")
+	require.NoError(t, err)
+	_, err = b.Do(context.Background(), "b")
+	require.NoError(t, err)
+
+	assert.Equal(t, []int{1, 1}, r.sizes())
+}
+
+func TestDo_BatchError_FailsEveryCaller(t *testing.T) {
+	boom := errors.New("boom")
+	b := microbatch.New(microbatch.Settings{Window: 20 * time.Millisecond},
+		func(ctx context.Context, keys []string) ([]string, []error, error) {
+			return nil, nil, boom
+		})
+
+	var wg sync.WaitGroup
+	for _, key := range []string{"a", "b"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := b.Do(context.Background(), key)
+			assert.ErrorIs(t, err, boom)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestDo_ItemError_FailsOnlyItsCaller(t *testing.T) {
+	notFound := errors.New("not found")
+	b := microbatch.New(microbatch.Settings{Window: 20 * time.Millisecond},
+		func(ctx context.Context, keys []string) ([]string, []error, error) {
+			values := make([]string, len(keys))
+			errs := make([]error, len(keys))
+			for i, key := range keys {
+				if key == "missing" {
+					errs[i] = notFound
+					continue
+				}
+				values[i] = "value-" + key
+			}
+			return values, errs, nil
+		})
+
+	var wg sync.WaitGroup
+	for _, key := range []string{"a", "missing", "b"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := b.Do(context.Background(), key)
+			if key == "missing" {
+				assert.ErrorIs(t, err, notFound)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "value-"+key, value)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestDo_WrongNumberOfValues_IsAnError(t *testing.T) {
+	b := microbatch.New(microbatch.Settings{Window: time.Millisecond},
+		func(ctx context.Context, keys []string) ([]string, []error, error) {
+			return []string{}, nil, nil
+		})
+
+	_, err := b.Do(context.Background(), "a")
+
+	assert.ErrorContains(t, err, "batch of 1 keys returned 0 values")
+}
+
+func TestDo_CancelledCaller_DoesNotCancelOthers(t *testing.T) {
+	var batchErr atomic.Value
+	b := microbatch.New(microbatch.Settings{Window: 10 * time.Millisecond},
+		func(ctx context.Context, keys []string) ([]string, []error, error) {
+			select {
+			case <-time.After(50 * time.Millisecond):
+			case <-ctx.Done():
+				batchErr.Store(ctx.Err())
+				return nil, nil, ctx.Err()
+			}
+			return make([]string, len(keys)), nil, nil
+		})
+
+	cancelled, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		_, err := b.Do(cancelled, "a")
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	}()
+	go func() {
+		defer wg.Done()
+		_, err := b.Do(context.Background(), "b")
+		assert.NoError(t, err)
+	}()
+	wg.Wait()
+
+	assert.Nil(t, batchErr.Load())
+}
+
+func TestDo_EveryCallerGone_CancelsBatch(t *testing.T) {
+	cancelled := make(chan struct{})
+	b := microbatch.New(microbatch.Settings{Window: time.Millisecond},
+		func(ctx context.Context, keys []string) ([]string, []error, error) {
+			<-ctx.Done()
+			close(cancelled)
+			return nil, nil, ctx.Err()
+		})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+
+	_, err := b.Do(ctx, "a")
+	assert.ErrorIs(t, err, context.Canceled)
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("batch was not cancelled after its only caller left")
+	}
+}
+
+func TestDo_BatchRunsUntilLatestCallerDeadline(t *testing.T) {
+	deadlines := make(chan time.Time, 1)
+	b := microbatch.New(microbatch.Settings{Window: 20 * time.Millisecond},
+		func(ctx context.Context, keys []string) ([]string, []error, error) {
+			deadline, _ := ctx.Deadline()
+			deadlines <- deadline
+			return make([]string, len(keys)), nil, nil
+		})
+
+	short, cancelShort := context.WithTimeout(context.Background(), time.Second)
+	defer cancelShort()
+	long, cancelLong := context.WithTimeout(context.Background(), time.Minute)
+	defer cancelLong()
+	longDeadline, _ := long.Deadline()
+
+	var wg sync.WaitGroup
+	for _, ctx := range []context.Context{short, long} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := b.Do(ctx, "a")
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, longDeadline, <-deadlines)
+}
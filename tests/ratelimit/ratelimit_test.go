@@ -0,0 +1,266 @@
+package ratelimit_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vwency/resilient-scatter-gather/internal/auth"
+	"github.com/vwency/resilient-scatter-gather/internal/metrics"
+	"github.com/vwency/resilient-scatter-gather/internal/ratelimit"
+	"github.com/vwency/resilient-scatter-gather/internal/requestid"
+	"github.com/vwency/resilient-scatter-gather/tests/testclock"
+)
+
+func TestMemoryStore_AllowsBurstThenRefills(t *testing.T) {
+	clock := testclock.New()
+	store := ratelimit.NewMemoryStoreWithClock(clock.Now)
+	rate := ratelimit.Rate{PerSecond: 2, Burst: 3}
+	ctx := context.Background()
+
+	for i := range 3 {
+		d, err := store.Take(ctx, "k", rate)
+		require.NoError(t, err)
+		assert.True(t, d.Allowed)
+		assert.Equal(t, 3, d.Limit)
+		assert.Equal(t, 2-i, d.Remaining)
+	}
+
+	d, err := store.Take(ctx, "k", rate)
+	require.NoError(t, err)
+	assert.False(t, d.Allowed)
+	assert.Equal(t, 500*time.Millisecond, d.RetryAfter)
+	assert.Equal(t, 1500*time.Millisecond, d.Reset)
+
+	clock.Advance(500 * time.Millisecond)
+	d, err = store.Take(ctx, "k", rate)
+	require.NoError(t, err)
+	assert.True(t, d.Allowed)
+
+	other, err := store.Take(ctx, "other", rate)
+	require.NoError(t, err)
+	assert.True(t, other.Allowed, "buckets are per key")
+}
+
+func TestMemoryStore_DropsFullBuckets(t *testing.T) {
+	clock := testclock.New()
+	store := ratelimit.NewMemoryStoreWithClock(clock.Now)
+	rate := ratelimit.Rate{PerSecond: 1, Burst: 5}
+
+	for _, key := range []string{"a", "b", "c"} {
+		_, err := store.Take(context.Background(), key, rate)
+		require.NoError(t, err)
+	}
+	require.Equal(t, 3, store.Len())
+
+	clock.Advance(2 * time.Minute)
+	_, err := store.Take(context.Background(), "d", rate)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, store.Len())
+}
+
+func rateLimited(t *testing.T, reg *prometheus.Registry, key string) float64 {
+	t.Helper()
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+
+	for _, family := range families {
+		if family.GetName() != "gateway_rate_limited_requests_total" {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			for _, pair := range m.GetLabel() {
+				if pair.GetName() == "key" && pair.GetValue() == key {
+					return m.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+	return 0
+}
+
+type failingStore struct{}
+
+func (failingStore) Take(ctx context.Context, key string, rate ratelimit.Rate) (ratelimit.Decision, error) {
+	return ratelimit.Decision{}, errors.New("store unreachable")
+}
+
+func okHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func serve(h http.Handler, req *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestMiddleware_OverLimit_Returns429WithHeaders(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := metrics.New(reg)
+	limiter := ratelimit.New(ratelimit.NewMemoryStore(), ratelimit.Settings{
+		IP: ratelimit.Rate{PerSecond: 0.5, Burst: 2},
+	}, ratelimit.WithMetrics(m))
+	h := limiter.IPMiddleware(okHandler())
+
+	newRequest := func() *http.Request {
+		req := httptest.NewRequest("GET", "/api/v1/chat/summary", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		return req
+	}
+
+	w := serve(h, newRequest())
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2", w.Header().Get(ratelimit.HeaderLimit))
+	assert.Equal(t, "1", w.Header().Get(ratelimit.HeaderRemaining))
+
+	assert.Equal(t, http.StatusOK, serve(h, newRequest()).Code)
+
+	w = serve(h, newRequest())
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+	assert.Equal(t, "0", w.Header().Get(ratelimit.HeaderRemaining))
+	assert.Equal(t, "4", w.Header().Get(ratelimit.HeaderReset))
+	assert.Contains(t, w.Body.String(), "rate limit exceeded")
+	assert.Equal(t, 1.0, rateLimited(t, reg, "ip"))
+}
+
+func TestIPMiddleware_RejectionCarriesRequestID(t *testing.T) {
+	limiter := ratelimit.New(ratelimit.NewMemoryStore(), ratelimit.Settings{
+		IP: ratelimit.Rate{PerSecond: 1, Burst: 1},
+	})
+	h := requestid.Middleware(limiter.IPMiddleware(okHandler()))
+
+	newRequest := func() *http.Request {
+		req := httptest.NewRequest("GET", "/api/v1/chat/summary", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		req.Header.Set(requestid.Header, "req-123")
+		return req
+	}
+
+	require.Equal(t, http.StatusOK, serve(h, newRequest()).Code)
+	w := serve(h, newRequest())
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "req-123", w.Header().Get(requestid.Header))
+	assert.Contains(t, w.Body.String(), `"request_id":"req-123"`)
+}
+
+func TestMiddleware_KeysByUser(t *testing.T) {
+	limiter := ratelimit.New(ratelimit.NewMemoryStore(), ratelimit.Settings{
+		User: ratelimit.Rate{PerSecond: 1, Burst: 1},
+	})
+	h := limiter.Middleware(okHandler())
+
+	request := func(subject, apiKey string) *http.Request {
+		req := httptest.NewRequest("GET", "/api/v1/chat/summary", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		if apiKey != "" {
+			req.Header.Set("X-API-Key", apiKey)
+		}
+		if subject != "" {
+			req = req.WithContext(auth.NewContext(req.Context(), auth.Identity{Subject: subject}))
+		}
+		return req
+	}
+
+	// Each user comes from the same address but lands in their own bucket.
+	assert.Equal(t, http.StatusOK, serve(h, request("alice", "")).Code)
+	assert.Equal(t, http.StatusOK, serve(h, request("bob", "key-1")).Code)
+	assert.Equal(t, http.StatusTooManyRequests, serve(h, request("alice", "key-2")).Code)
+
+	// API keys are not verified, so they get no bucket of their own.
+	for range 3 {
+		assert.Equal(t, http.StatusOK, serve(h, request("", "key-1")).Code, "unauthenticated requests are left to IPMiddleware")
+		assert.Equal(t, http.StatusOK, serve(h, request("", "")).Code, "unauthenticated requests are left to IPMiddleware")
+	}
+}
+
+func TestIPMiddleware_MadeUpAPIKeysShareAddressLimit(t *testing.T) {
+	limiter := ratelimit.New(ratelimit.NewMemoryStore(), ratelimit.Settings{
+		User: ratelimit.Rate{PerSecond: 50, Burst: 100},
+		IP:   ratelimit.Rate{PerSecond: 1, Burst: 2},
+	})
+	h := limiter.IPMiddleware(limiter.Middleware(okHandler()))
+
+	codes := make([]int, 0, 3)
+	for i := range 3 {
+		req := httptest.NewRequest("GET", "/api/v1/chat/summary", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		req.Header.Set("X-API-Key", fmt.Sprintf("random-%d", i))
+		codes = append(codes, serve(h, req).Code)
+	}
+
+	assert.Equal(t, []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests}, codes)
+}
+
+func TestIPMiddleware_LimitsInvalidTokensInFrontOfAuth(t *testing.T) {
+	verifier, err := auth.NewVerifier(auth.Settings{}, auth.SecretKey([]byte("0123456789abcdef0123456789abcdef")))
+	require.NoError(t, err)
+	limiter := ratelimit.New(ratelimit.NewMemoryStore(), ratelimit.Settings{
+		IP: ratelimit.Rate{PerSecond: 1, Burst: 2},
+	})
+	h := limiter.IPMiddleware(auth.Middleware(verifier, limiter.Middleware(okHandler())))
+
+	codes := make([]int, 0, 3)
+	for range 3 {
+		req := httptest.NewRequest("GET", "/api/v1/chat/summary", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		req.Header.Set("Authorization", "Bearer not.a.token")
+		codes = append(codes, serve(h, req).Code)
+	}
+
+	assert.Equal(t, []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusTooManyRequests}, codes)
+}
+
+func TestMiddleware_TrustForwardedFor_UsesLastEntry(t *testing.T) {
+	limiter := ratelimit.New(ratelimit.NewMemoryStore(), ratelimit.Settings{
+		IP:                ratelimit.Rate{PerSecond: 1, Burst: 1},
+		TrustForwardedFor: true,
+	})
+	h := limiter.IPMiddleware(okHandler())
+
+	request := func(forwardedFor string) *http.Request {
+		req := httptest.NewRequest("GET", "/api/v1/chat/summary", nil)
+		req.RemoteAddr = "192.168.0.1:1234"
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		return req
+	}
+
+	assert.Equal(t, http.StatusOK, serve(h, request("1.1.1.1, 203.0.113.7")).Code)
+	assert.Equal(t, http.StatusOK, serve(h, request("203.0.113.8")).Code)
+	// A spoofed first entry does not get the client a fresh bucket.
+	assert.Equal(t, http.StatusTooManyRequests, serve(h, request("9.9.9.9, 203.0.113.7")).Code)
+}
+
+func TestMiddleware_ZeroRate_IsUnlimited(t *testing.T) {
+	limiter := ratelimit.New(ratelimit.NewMemoryStore(), ratelimit.Settings{})
+	h := limiter.IPMiddleware(limiter.Middleware(okHandler()))
+
+	for range 100 {
+		w := serve(h, httptest.NewRequest("GET", "/api/v1/chat/summary", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get(ratelimit.HeaderLimit))
+	}
+}
+
+func TestMiddleware_StoreError_AllowsRequest(t *testing.T) {
+	limiter := ratelimit.New(failingStore{}, ratelimit.Settings{
+		IP: ratelimit.Rate{PerSecond: 1, Burst: 1},
+	})
+
+	w := serve(limiter.IPMiddleware(okHandler()), httptest.NewRequest("GET", "/api/v1/chat/summary", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -0,0 +1,67 @@
+package requestid_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vwency/resilient-scatter-gather/internal/requestid"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestResolve_KeepsValidID(t *testing.T) {
+	assert.Equal(t, "req-123", requestid.Resolve("req-123"))
+}
+
+func TestResolve_ReplacesMissingOrInvalidID(t *testing.T) {
+	for _, id := range []string{"", "has space", "line\nbreak", strings.Repeat("a", 129)} {
+		resolved := requestid.Resolve(id)
+		assert.NotEqual(t, id, resolved)
+		assert.Len(t, resolved, 32)
+	}
+}
+
+func TestNew_ReturnsDistinctIDs(t *testing.T) {
+	assert.NotEqual(t, requestid.New(), requestid.New())
+}
+
+func TestAppendToOutgoing_AddsMetadata(t *testing.T) {
+	ctx := requestid.NewContext(context.Background(), "req-123")
+
+	md, ok := metadata.FromOutgoingContext(requestid.AppendToOutgoing(ctx))
+
+	assert.True(t, ok)
+	assert.Equal(t, []string{"req-123"}, md.Get(requestid.MetadataKey))
+}
+
+func TestAppendToOutgoing_WithoutID_LeavesContext(t *testing.T) {
+	ctx := context.Background()
+
+	_, ok := metadata.FromOutgoingContext(requestid.AppendToOutgoing(ctx))
+
+	assert.False(t, ok)
+}
+
+func TestMiddleware_ResolvesIDForNextHandler(t *testing.T) {
+	var seen string
+	h := requestid.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen, _ = requestid.FromContext(r.Context())
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+
+	req := httptest.NewRequest("GET", "/api/v1/chat/summary", nil)
+	req.Header.Set(requestid.Header, "req-123")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	assert.Equal(t, "req-123", seen)
+	assert.Equal(t, "req-123", w.Header().Get(requestid.Header))
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/chat/summary", nil))
+
+	assert.Len(t, w.Header().Get(requestid.Header), 32, "a missing ID is generated")
+}
@@ -0,0 +1,108 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vwency/resilient-scatter-gather/internal/retry"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newTestRetrier(budget *retry.Budget) *retry.Retrier {
+	return retry.New("test", retry.Policy{
+		MaxAttempts:    3,
+		InitialBackoff: 5 * time.Millisecond,
+		MaxBackoff:     20 * time.Millisecond,
+		Multiplier:     2,
+		Jitter:         0.5,
+		MinAttemptTime: 10 * time.Millisecond,
+		RetryableCodes: []codes.Code{codes.Unavailable},
+	}, budget)
+}
+
+// failing returns a call that fails with err the first n times.
+func failing(n int, err error, calls *int) func(ctx context.Context) (string, error) {
+	return func(ctx context.Context) (string, error) {
+		*calls++
+		if *calls <= n {
+			return "", err
+		}
+		return "ok", nil
+	}
+}
+
+func TestDo_TransientUnavailable_Retries(t *testing.T) {
+	r := newTestRetrier(retry.NewBudget(0.1, 10))
+	calls := 0
+
+	value, err := retry.Do(context.Background(), r, failing(2, status.Error(codes.Unavailable, "down"), &calls))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", value)
+	assert.Equal(t, 3, calls)
+}
+
+func TestDo_MaxAttemptsReached_ReturnsLastError(t *testing.T) {
+	r := newTestRetrier(retry.NewBudget(0.1, 10))
+	calls := 0
+
+	_, err := retry.Do(context.Background(), r, failing(5, status.Error(codes.Unavailable, "down"), &calls))
+
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, 3, calls)
+}
+
+func TestDo_NonRetryableCode_DoesNotRetry(t *testing.T) {
+	r := newTestRetrier(retry.NewBudget(0.1, 10))
+	calls := 0
+
+	_, err := retry.Do(context.Background(), r, failing(1, status.Error(codes.InvalidArgument, "bad"), &calls))
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	assert.Equal(t, 1, calls)
+
+	calls = 0
+	_, err = retry.Do(context.Background(), r, failing(1, errors.New("plain"), &calls))
+	assert.Error(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestDo_DeadlineTooClose_DoesNotRetry(t *testing.T) {
+	r := newTestRetrier(retry.NewBudget(0.1, 10))
+	calls := 0
+
+	ctx, cancel := context.WithTimeout(context.Background(), 12*time.Millisecond)
+	defer cancel()
+
+	_, err := retry.Do(ctx, r, failing(1, status.Error(codes.Unavailable, "down"), &calls))
+
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, 1, calls)
+}
+
+func TestDo_BudgetExhausted_StopsRetrying(t *testing.T) {
+	r := newTestRetrier(retry.NewBudget(0, 1))
+	calls := 0
+
+	_, err := retry.Do(context.Background(), r, failing(1, status.Error(codes.Unavailable, "down"), &calls))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+
+	calls = 0
+	_, err = retry.Do(context.Background(), r, failing(1, status.Error(codes.Unavailable, "down"), &calls))
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, 1, calls)
+}
+
+func TestParseCodes_InvalidName_ReturnsError(t *testing.T) {
+	parsed, err := retry.ParseCodes([]string{"unavailable", "RESOURCE_EXHAUSTED"})
+	assert.NoError(t, err)
+	assert.Equal(t, []codes.Code{codes.Unavailable, codes.ResourceExhausted}, parsed)
+
+	_, err = retry.ParseCodes([]string{"NOT_A_CODE"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,327 @@
+package scatter_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vwency/resilient-scatter-gather/internal/scatter"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRun_AllLegsSucceed_ReturnsTypedValues(t *testing.T) {
+	g := scatter.New()
+
+	name := scatter.Register(g, scatter.Spec{Name: "name", Criticality: scatter.Required},
+		func(ctx context.Context) (string, error) {
+			return "alice", nil
+		})
+	count := scatter.Register(g, scatter.Spec{Name: "count", Criticality: scatter.Optional},
+		func(ctx context.Context) (int, error) {
+			return 42, nil
+		})
+
+	report, err := g.Run(context.Background())
+
+	assert.NoError(t, err)
+	assert.False(t, report.Degraded)
+	assert.Len(t, report.Legs, 2)
+	assert.Equal(t, "alice", name.Value())
+	assert.Equal(t, 42, count.Value())
+	assert.NoError(t, name.Err())
+	assert.NoError(t, count.Err())
+}
+
+func TestRun_RequiredLegFails_ReturnsLegError(t *testing.T) {
+	g := scatter.New()
+
+	scatter.Register(g, scatter.Spec{Name: "profile", Criticality: scatter.Required},
+		func(ctx context.Context) (string, error) {
+			return "", errors.New("backend down")
+		})
+	scatter.Register(g, scatter.Spec{Name: "extra", Criticality: scatter.Optional},
+		func(ctx context.Context) (string, error) {
+			return "extra", nil
+		})
+
+	_, err := g.Run(context.Background())
+
+	var legErr *scatter.LegError
+	assert.ErrorAs(t, err, &legErr)
+	assert.Equal(t, "profile", legErr.Leg)
+	assert.Contains(t, err.Error(), "backend down")
+}
+
+func TestRun_OptionalLegFails_ReturnsDegraded(t *testing.T) {
+	g := scatter.New()
+
+	profile := scatter.Register(g, scatter.Spec{Name: "profile", Criticality: scatter.Required},
+		func(ctx context.Context) (string, error) {
+			return "alice", nil
+		})
+	extra := scatter.Register(g, scatter.Spec{Name: "extra", Criticality: scatter.Optional},
+		func(ctx context.Context) (string, error) {
+			return "", errors.New("extra down")
+		})
+
+	report, err := g.Run(context.Background())
+
+	assert.NoError(t, err)
+	assert.True(t, report.Degraded)
+	assert.Equal(t, "alice", profile.Value())
+	assert.Error(t, extra.Err())
+	assert.Empty(t, extra.Value())
+}
+
+func TestRun_LegTimeout_AppliesOnlyToThatLeg(t *testing.T) {
+	g := scatter.New()
+
+	fast := scatter.Register(g, scatter.Spec{Name: "fast", Criticality: scatter.Required},
+		func(ctx context.Context) (string, error) {
+			time.Sleep(30 * time.Millisecond)
+			return "fast", nil
+		})
+	slow := scatter.Register(g, scatter.Spec{Name: "slow", Criticality: scatter.Optional, Timeout: 10 * time.Millisecond},
+		func(ctx context.Context) (string, error) {
+			<-ctx.Done()
+			return "", ctx.Err()
+		})
+
+	report, err := g.Run(context.Background())
+
+	assert.NoError(t, err)
+	assert.True(t, report.Degraded)
+	assert.Equal(t, "fast", fast.Value())
+	assert.ErrorIs(t, slow.Err(), context.DeadlineExceeded)
+}
+
+func TestRun_ContextExpires_UnfinishedRequiredLegFails(t *testing.T) {
+	g := scatter.New()
+
+	scatter.Register(g, scatter.Spec{Name: "stuck", Criticality: scatter.Required},
+		func(ctx context.Context) (string, error) {
+			time.Sleep(200 * time.Millisecond)
+			return "too late", nil
+		})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := g.Run(ctx)
+
+	assert.Less(t, time.Since(start), 100*time.Millisecond)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestRun_GRPCDeadlineExceeded_ReportsTimeout(t *testing.T) {
+	g := scatter.New()
+
+	scatter.Register(g, scatter.Spec{Name: "vector", Criticality: scatter.Optional},
+		func(ctx context.Context) (string, error) {
+			return "", status.Error(codes.DeadlineExceeded, "deadline exceeded")
+		})
+
+	report, err := g.Run(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, scatter.StatusTimeout, report.Legs[0].Status)
+}
+
+func TestRun_RequiredLegFails_CancelsSiblings(t *testing.T) {
+	g := scatter.New()
+	siblingErr := make(chan error, 1)
+
+	scatter.Register(g, scatter.Spec{Name: "profile", Criticality: scatter.Required},
+		func(ctx context.Context) (string, error) {
+			time.Sleep(10 * time.Millisecond)
+			return "", errors.New("backend down")
+		})
+	scatter.Register(g, scatter.Spec{Name: "extra", Criticality: scatter.Optional},
+		func(ctx context.Context) (string, error) {
+			select {
+			case <-ctx.Done():
+				siblingErr <- ctx.Err()
+				return "", ctx.Err()
+			case <-time.After(time.Second):
+				siblingErr <- nil
+				return "extra", nil
+			}
+		})
+
+	start := time.Now()
+	_, err := g.Run(context.Background())
+
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), 100*time.Millisecond)
+
+	select {
+	case err := <-siblingErr:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("sibling leg was not cancelled")
+	}
+}
+
+func TestRun_LegMarkedCached_ReportsCachedStatus(t *testing.T) {
+	g := scatter.New()
+
+	scatter.Register(g, scatter.Spec{Name: "profile", Criticality: scatter.Required},
+		func(ctx context.Context) (string, error) {
+			scatter.MarkCached(ctx)
+			return "alice", nil
+		})
+	scatter.Register(g, scatter.Spec{Name: "extra", Criticality: scatter.Optional},
+		func(ctx context.Context) (string, error) {
+			return "", errors.New("extra down")
+		})
+
+	report, err := g.Run(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, scatter.StatusCached, report.Legs[0].Status)
+	assert.Equal(t, scatter.StatusError, report.Legs[1].Status)
+}
+
+func TestRun_RequiredLegFails_ReportsUnfinishedLegsAsSkipped(t *testing.T) {
+	g := scatter.New()
+
+	scatter.Register(g, scatter.Spec{Name: "profile", Criticality: scatter.Required},
+		func(ctx context.Context) (string, error) {
+			return "", errors.New("backend down")
+		})
+	scatter.Register(g, scatter.Spec{Name: "extra", Criticality: scatter.Optional},
+		func(ctx context.Context) (string, error) {
+			<-ctx.Done()
+			return "", ctx.Err()
+		})
+
+	report, err := g.Run(context.Background())
+
+	assert.Error(t, err)
+	assert.Equal(t, scatter.StatusError, report.Legs[0].Status)
+	assert.Equal(t, scatter.StatusSkipped, report.Legs[1].Status)
+}
+
+func TestRun_LegWithTimeoutMarkedCached_ReportsCachedStatus(t *testing.T) {
+	g := scatter.New()
+
+	scatter.Register(g, scatter.Spec{Name: "profile", Criticality: scatter.Required, Timeout: time.Second},
+		func(ctx context.Context) (string, error) {
+			scatter.MarkCached(ctx)
+			return "alice", nil
+		})
+
+	report, err := g.Run(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, scatter.StatusCached, report.Legs[0].Status)
+}
+
+// legDeadline registers a leg with budget that reports how long it was given.
+func legDeadline(g *scatter.Gather, budget scatter.Budget) *scatter.Leg[time.Duration] {
+	return scatter.Register(g, scatter.Spec{Name: "leg", Criticality: scatter.Optional, Budget: budget},
+		func(ctx context.Context) (time.Duration, error) {
+			deadline, ok := ctx.Deadline()
+			if !ok {
+				return 0, nil
+			}
+			return time.Until(deadline), nil
+		})
+}
+
+func TestRun_LegBudget_TakesShareOfRemainingTime(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	g := scatter.New()
+
+	share := legDeadline(g, scatter.Budget{Share: 0.5})
+	ceiling := legDeadline(g, scatter.Budget{Share: 0.5, Ceiling: 100 * time.Millisecond})
+	floor := legDeadline(g, scatter.Budget{Share: 0.01, Floor: 200 * time.Millisecond})
+	unbounded := legDeadline(g, scatter.Budget{})
+
+	_, err := g.Run(ctx)
+
+	assert.NoError(t, err)
+	assert.InDelta(t, 500*time.Millisecond, share.Value(), float64(50*time.Millisecond))
+	assert.InDelta(t, 100*time.Millisecond, ceiling.Value(), float64(20*time.Millisecond))
+	assert.InDelta(t, 200*time.Millisecond, floor.Value(), float64(20*time.Millisecond))
+	assert.InDelta(t, time.Second, unbounded.Value(), float64(50*time.Millisecond))
+}
+
+func TestRun_LegBudget_WithoutDeadlineUsesCeiling(t *testing.T) {
+	g := scatter.New()
+
+	leg := legDeadline(g, scatter.Budget{Share: 0.5, Ceiling: 100 * time.Millisecond})
+
+	_, err := g.Run(context.Background())
+
+	assert.NoError(t, err)
+	assert.InDelta(t, 100*time.Millisecond, leg.Value(), float64(20*time.Millisecond))
+}
+
+func TestRun_LegBudget_ShorterThanTimeoutWins(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	g := scatter.New()
+
+	leg := scatter.Register(g, scatter.Spec{
+		Name:        "leg",
+		Criticality: scatter.Required,
+		Timeout:     50 * time.Millisecond,
+		Budget:      scatter.Budget{Share: 0.5},
+	}, func(ctx context.Context) (time.Duration, error) {
+		deadline, _ := ctx.Deadline()
+		return time.Until(deadline), nil
+	})
+
+	_, err := g.Run(ctx)
+
+	assert.NoError(t, err)
+	assert.InDelta(t, 50*time.Millisecond, leg.Value(), float64(20*time.Millisecond))
+}
+
+func TestRun_LegBudget_SkipsLegBelowMinUseful(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	g := scatter.New()
+
+	started := false
+	scatter.Register(g, scatter.Spec{Name: "profile", Criticality: scatter.Required},
+		func(ctx context.Context) (string, error) {
+			return "alice", nil
+		})
+	extra := scatter.Register(g, scatter.Spec{Name: "extra", Criticality: scatter.Optional, Budget: scatter.Budget{MinUseful: 50 * time.Millisecond}},
+		func(ctx context.Context) (string, error) {
+			started = true
+			return "extra", nil
+		})
+
+	report, err := g.Run(ctx)
+
+	assert.NoError(t, err)
+	assert.True(t, report.Degraded)
+	assert.False(t, started)
+	assert.ErrorIs(t, extra.Err(), scatter.ErrBudgetExhausted)
+	assert.Equal(t, scatter.StatusSkipped, report.Legs[1].Status)
+}
+
+func TestRun_LegBudget_RequiredLegBelowMinUsefulFailsGather(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	g := scatter.New()
+
+	scatter.Register(g, scatter.Spec{Name: "profile", Criticality: scatter.Required, Budget: scatter.Budget{MinUseful: 50 * time.Millisecond}},
+		func(ctx context.Context) (string, error) {
+			return "alice", nil
+		})
+
+	_, err := g.Run(ctx)
+
+	var legErr *scatter.LegError
+	assert.ErrorAs(t, err, &legErr)
+	assert.ErrorIs(t, err, scatter.ErrBudgetExhausted)
+}
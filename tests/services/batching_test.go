@@ -0,0 +1,171 @@
+package services_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vwency/resilient-scatter-gather/internal/microbatch"
+	"github.com/vwency/resilient-scatter-gather/internal/services"
+	pb_permissions "github.com/vwency/resilient-scatter-gather/proto/permissions"
+	pb_user "github.com/vwency/resilient-scatter-gather/proto/user"
+	pb_vector "github.com/vwency/resilient-scatter-gather/proto/vector"
+	statuspb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUserServiceBatcher_ConcurrentGetUser_SendsOneBatchRPC(t *testing.T) {
+	var mu sync.Mutex
+	var requests []*pb_user.BatchGetUsersRequest
+
+	client := services.NewUserServiceClient(&fakeUserClient{
+		batchGetUsers: func(ctx context.Context, in *pb_user.BatchGetUsersRequest) (*pb_user.BatchGetUsersResponse, error) {
+			mu.Lock()
+			requests = append(requests, in)
+			mu.Unlock()
+
+			users := make([]*pb_user.GetUserResponse, 0, len(in.UserIds))
+			for _, id := range in.UserIds {
+				users = append(users, &pb_user.GetUserResponse{UserId: id, Username: "name-" + id})
+			}
+			return &pb_user.BatchGetUsersResponse{Users: users}, nil
+		},
+	})
+	batcher := services.NewUserServiceBatcher(client, microbatch.Settings{Window: 50 * time.Millisecond})
+
+	var wg sync.WaitGroup
+	for _, id := range []string{"u1", "u2", "u3"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			user, err := batcher.GetUser(context.Background(), id)
+			require.NoError(t, err)
+			assert.Equal(t, "name-"+id, user.Username)
+		}()
+	}
+	wg.Wait()
+
+	require.Len(t, requests, 1)
+	assert.ElementsMatch(t, []string{"u1", "u2", "u3"}, requests[0].UserIds)
+}
+
+func TestUserServiceBatcher_ItemError_FailsOnlyItsCaller(t *testing.T) {
+	client := services.NewUserServiceClient(&fakeUserClient{
+		batchGetUsers: func(ctx context.Context, in *pb_user.BatchGetUsersRequest) (*pb_user.BatchGetUsersResponse, error) {
+			resp := &pb_user.BatchGetUsersResponse{}
+			for _, id := range in.UserIds {
+				st := &statuspb.Status{}
+				if id == "missing" {
+					st = status.New(codes.NotFound, "no such user").Proto()
+				}
+				resp.Users = append(resp.Users, &pb_user.GetUserResponse{UserId: id})
+				resp.Statuses = append(resp.Statuses, st)
+			}
+			return resp, nil
+		},
+	})
+	batcher := services.NewUserServiceBatcher(client, microbatch.Settings{Window: 50 * time.Millisecond})
+
+	var wg sync.WaitGroup
+	for _, id := range []string{"u1", "missing", "u2"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			user, err := batcher.GetUser(context.Background(), id)
+			if id == "missing" {
+				assert.Equal(t, codes.NotFound, status.Code(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, id, user.UserId)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestUserServiceServer_BatchGetUsers_ReportsStatusPerItem(t *testing.T) {
+	resp, err := services.NewUserServiceServer().BatchGetUsers(context.Background(), &pb_user.BatchGetUsersRequest{UserIds: []string{"u1", "u2"}})
+
+	require.NoError(t, err)
+	require.Len(t, resp.Statuses, 2)
+	for _, st := range resp.Statuses {
+		assert.Equal(t, int32(codes.OK), st.Code)
+	}
+}
+
+func TestPermissionsServiceBatcher_KeysByUserAndResource(t *testing.T) {
+	var mu sync.Mutex
+	var checks []*pb_permissions.CheckAccessRequest
+
+	client := services.NewPermissionsServiceClient(&fakePermissionsClient{
+		batchCheckAccess: func(ctx context.Context, in *pb_permissions.BatchCheckAccessRequest) (*pb_permissions.BatchCheckAccessResponse, error) {
+			mu.Lock()
+			checks = append(checks, in.Checks...)
+			mu.Unlock()
+
+			results := make([]*pb_permissions.CheckAccessResponse, 0, len(in.Checks))
+			for _, check := range in.Checks {
+				results = append(results, &pb_permissions.CheckAccessResponse{Allowed: check.ResourceId == "open"})
+			}
+			return &pb_permissions.BatchCheckAccessResponse{Results: results}, nil
+		},
+	})
+	batcher := services.NewPermissionsServiceBatcher(client, microbatch.Settings{Window: 50 * time.Millisecond})
+
+	var wg sync.WaitGroup
+	for _, resource := range []string{"open", "closed", "open"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := batcher.CheckAccess(context.Background(), "user-1", resource)
+			require.NoError(t, err)
+			assert.Equal(t, resource == "open", resp.Allowed)
+		}()
+	}
+	wg.Wait()
+
+	require.Len(t, checks, 2)
+	for _, check := range checks {
+		assert.Equal(t, "user-1", check.UserId)
+		assert.Equal(t, "read", check.Action)
+	}
+}
+
+func TestVectorMemoryServiceClient_BatchGetContext_ShortResponse_IsInternalError(t *testing.T) {
+	client := services.NewVectorMemoryServiceClient(&fakeVectorClient{
+		batchGetContext: func(ctx context.Context, in *pb_vector.BatchGetContextRequest) (*pb_vector.BatchGetContextResponse, error) {
+			return &pb_vector.BatchGetContextResponse{Contexts: []*pb_vector.GetContextResponse{{}}}, nil
+		},
+	})
+
+	_, _, err := client.BatchGetContext(context.Background(), []string{"c1", "c2"})
+
+	var backendErr *services.BackendError
+	require.ErrorAs(t, err, &backendErr)
+	assert.Equal(t, "VectorMemoryService", backendErr.Service)
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
+
+func TestVectorMemoryServiceBatcher_BackendError_ReachesEveryCaller(t *testing.T) {
+	client := services.NewVectorMemoryServiceClient(&fakeVectorClient{
+		batchGetContext: func(ctx context.Context, in *pb_vector.BatchGetContextRequest) (*pb_vector.BatchGetContextResponse, error) {
+			return nil, status.Error(codes.Unavailable, "down")
+		},
+	})
+	batcher := services.NewVectorMemoryServiceBatcher(client, microbatch.Settings{Window: 20 * time.Millisecond})
+
+	var wg sync.WaitGroup
+	for _, chatID := range []string{"c1", "c2"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := batcher.GetContext(context.Background(), chatID)
+			assert.ErrorIs(t, err, services.ErrUnavailable)
+		}()
+	}
+	wg.Wait()
+}
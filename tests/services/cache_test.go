@@ -0,0 +1,87 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/vwency/resilient-scatter-gather/internal/cache"
+	"github.com/vwency/resilient-scatter-gather/internal/services"
+	pb_permissions "github.com/vwency/resilient-scatter-gather/proto/permissions"
+	pb_user "github.com/vwency/resilient-scatter-gather/proto/user"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUserServiceCache_FreshEntry_SkipsBackend(t *testing.T) {
+	mockUser := new(UserService)
+	userResp := &pb_user.GetUserResponse{UserId: "user123"}
+	mockUser.On("GetUser", mock.Anything, "user123").Return(userResp, nil).Once()
+
+	svc := services.NewUserServiceCache(mockUser, cache.Settings{TTL: time.Second, MaxEntries: 10})
+
+	for i := 0; i < 3; i++ {
+		resp, err := svc.GetUser(context.Background(), "user123")
+		assert.NoError(t, err)
+		assert.Same(t, userResp, resp)
+	}
+
+	mockUser.AssertExpectations(t)
+}
+
+func TestPermissionsServiceCache_StaleTTL_IsRefused(t *testing.T) {
+	_, err := services.NewPermissionsServiceCache(new(PermissionsService), cache.Settings{
+		TTL:      time.Second,
+		StaleTTL: time.Second,
+	})
+
+	assert.ErrorIs(t, err, services.ErrStalePermissions)
+}
+
+func TestPermissionsServiceCache_BackendOutage_DoesNotServeExpiredDecision(t *testing.T) {
+	mockPermissions := new(PermissionsService)
+	permResp := &pb_permissions.CheckAccessResponse{Allowed: true}
+	mockPermissions.On("CheckAccess", mock.Anything, "user123", "chat1").Return(permResp, nil).Once()
+	mockPermissions.On("CheckAccess", mock.Anything, "user123", "chat1").Return(nil, status.Error(codes.Unavailable, "down"))
+
+	svc, err := services.NewPermissionsServiceCache(mockPermissions, cache.Settings{
+		TTL:        10 * time.Millisecond,
+		MaxEntries: 10,
+	})
+	require.NoError(t, err)
+
+	_, err = svc.CheckAccess(context.Background(), "user123", "chat1")
+	assert.NoError(t, err)
+
+	time.Sleep(15 * time.Millisecond)
+
+	resp, err := svc.CheckAccess(context.Background(), "user123", "chat1")
+	assert.Error(t, err)
+	assert.Nil(t, resp)
+}
+
+func TestPermissionsServiceCache_DifferentResources_CachedSeparately(t *testing.T) {
+	mockPermissions := new(PermissionsService)
+	allowed := &pb_permissions.CheckAccessResponse{Allowed: true}
+	denied := &pb_permissions.CheckAccessResponse{Allowed: false}
+	mockPermissions.On("CheckAccess", mock.Anything, "user123", "chat1").Return(allowed, nil).Once()
+	mockPermissions.On("CheckAccess", mock.Anything, "user123", "chat2").Return(denied, nil).Once()
+
+	svc, err := services.NewPermissionsServiceCache(mockPermissions, cache.Settings{TTL: time.Second, MaxEntries: 10})
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		resp, err := svc.CheckAccess(context.Background(), "user123", "chat1")
+		assert.NoError(t, err)
+		assert.True(t, resp.Allowed)
+
+		resp, err = svc.CheckAccess(context.Background(), "user123", "chat2")
+		assert.NoError(t, err)
+		assert.False(t, resp.Allowed)
+	}
+
+	mockPermissions.AssertExpectations(t)
+}
@@ -0,0 +1,120 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/vwency/resilient-scatter-gather/internal/breaker"
+	"github.com/vwency/resilient-scatter-gather/internal/services"
+	pb_permissions "github.com/vwency/resilient-scatter-gather/proto/permissions"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newTestBreaker() *breaker.CircuitBreaker {
+	return breaker.New("test", breaker.Settings{
+		FailureRatio: 0.5,
+		MinRequests:  2,
+		Window:       time.Second,
+		CoolDown:     time.Second,
+	})
+}
+
+func TestUserServiceBreaker_BackendFailing_ShortCircuits(t *testing.T) {
+	mockUser := new(UserService)
+	mockUser.On("GetUser", mock.Anything, "user123").Return(nil, status.Error(codes.Unavailable, "down")).Twice()
+
+	svc := services.NewUserServiceBreaker(mockUser, newTestBreaker())
+
+	for i := 0; i < 2; i++ {
+		_, err := svc.GetUser(context.Background(), "user123")
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+	}
+
+	start := time.Now()
+	_, err := svc.GetUser(context.Background(), "user123")
+
+	assert.ErrorIs(t, err, breaker.ErrOpen)
+	assert.Less(t, time.Since(start), 10*time.Millisecond)
+	mockUser.AssertNumberOfCalls(t, "GetUser", 2)
+}
+
+func TestPermissionsServiceBreaker_ClientErrors_DoNotTrip(t *testing.T) {
+	mockPermissions := new(PermissionsService)
+	mockPermissions.On("CheckAccess", mock.Anything, "user123", "chat1").Return(nil, status.Error(codes.InvalidArgument, "bad id")).Times(3)
+	mockPermissions.On("CheckAccess", mock.Anything, "user123", "chat2").Return(nil, context.Canceled).Times(3)
+
+	cb := newTestBreaker()
+	svc := services.NewPermissionsServiceBreaker(mockPermissions, cb)
+
+	for i := 0; i < 3; i++ {
+		_, err := svc.CheckAccess(context.Background(), "user123", "chat1")
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		_, err = svc.CheckAccess(context.Background(), "user123", "chat2")
+		assert.ErrorIs(t, err, context.Canceled)
+	}
+
+	assert.Equal(t, breaker.Closed, cb.State())
+	mockPermissions.AssertExpectations(t)
+}
+
+func TestPermissionsServiceBreaker_Success_PassesResponseThrough(t *testing.T) {
+	mockPermissions := new(PermissionsService)
+	permResp := &pb_permissions.CheckAccessResponse{Allowed: true}
+	mockPermissions.On("CheckAccess", mock.Anything, "user123", "chat1").Return(permResp, nil)
+
+	svc := services.NewPermissionsServiceBreaker(mockPermissions, newTestBreaker())
+
+	resp, err := svc.CheckAccess(context.Background(), "user123", "chat1")
+
+	assert.NoError(t, err)
+	assert.Same(t, permResp, resp)
+}
+
+func TestVectorMemoryServiceBreaker_Open_ReturnsErrOpen(t *testing.T) {
+	mockVector := new(VectorMemoryService)
+	mockVector.On("GetContext", mock.Anything, "chat1").Return(nil, errors.New("boom")).Twice()
+
+	svc := services.NewVectorMemoryServiceBreaker(mockVector, newTestBreaker())
+
+	for i := 0; i < 2; i++ {
+		_, err := svc.GetContext(context.Background(), "chat1")
+		assert.Error(t, err)
+	}
+
+	_, err := svc.GetContext(context.Background(), "chat1")
+	assert.ErrorIs(t, err, breaker.ErrOpen)
+	mockVector.AssertExpectations(t)
+}
+
+func TestUserServiceBreaker_CanceledProbe_DoesNotCloseBreaker(t *testing.T) {
+	mockUser := new(UserService)
+	mockUser.On("GetUser", mock.Anything, "user123").Return(nil, status.Error(codes.Unavailable, "down")).Twice()
+	mockUser.On("GetUser", mock.Anything, "user123").Return(nil, status.Error(codes.Canceled, "context canceled")).Once()
+
+	cb := breaker.New("test", breaker.Settings{
+		FailureRatio:        0.5,
+		MinRequests:         2,
+		Window:              time.Second,
+		CoolDown:            20 * time.Millisecond,
+		HalfOpenMaxRequests: 1,
+	})
+	svc := services.NewUserServiceBreaker(mockUser, cb)
+
+	for i := 0; i < 2; i++ {
+		_, _ = svc.GetUser(context.Background(), "user123")
+	}
+	time.Sleep(30 * time.Millisecond)
+
+	_, err := svc.GetUser(context.Background(), "user123")
+
+	assert.Equal(t, codes.Canceled, status.Code(err))
+	assert.Equal(t, breaker.HalfOpen, cb.State())
+	_, err = cb.Allow()
+	assert.NoError(t, err, "the probe slot is free again")
+}
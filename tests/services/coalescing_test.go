@@ -0,0 +1,37 @@
+package services_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/vwency/resilient-scatter-gather/internal/services"
+	pb_vector "github.com/vwency/resilient-scatter-gather/proto/vector"
+)
+
+func TestVectorMemoryServiceCoalescer_ConcurrentSameChat_SingleBackendCall(t *testing.T) {
+	mockVector := new(VectorMemoryService)
+	vectorResp := &pb_vector.GetContextResponse{TotalCount: 1}
+	mockVector.On("GetContext", mock.Anything, "chat1").Return(vectorResp, nil).Run(func(args mock.Arguments) {
+		time.Sleep(30 * time.Millisecond)
+	}).Once()
+
+	svc := services.NewVectorMemoryServiceCoalescer(mockVector)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := svc.GetContext(context.Background(), "chat1")
+			assert.NoError(t, err)
+			assert.Same(t, vectorResp, resp)
+		}()
+	}
+	wg.Wait()
+
+	mockVector.AssertExpectations(t)
+}
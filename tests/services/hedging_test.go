@@ -0,0 +1,41 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/vwency/resilient-scatter-gather/internal/hedge"
+	"github.com/vwency/resilient-scatter-gather/internal/services"
+	pb_vector "github.com/vwency/resilient-scatter-gather/proto/vector"
+)
+
+func TestVectorMemoryServiceHedger_SlowCall_IssuesSecondRequest(t *testing.T) {
+	mockVector := new(VectorMemoryService)
+
+	slowResp := &pb_vector.GetContextResponse{TotalCount: 1}
+	fastResp := &pb_vector.GetContextResponse{TotalCount: 2}
+	mockVector.On("GetContext", mock.Anything, "chat1").Return(slowResp, nil).Run(func(args mock.Arguments) {
+		time.Sleep(150 * time.Millisecond)
+	}).Once()
+	mockVector.On("GetContext", mock.Anything, "chat1").Return(fastResp, nil).Once()
+
+	h := hedge.New("vector", hedge.Settings{
+		Percentile:         0.95,
+		InitialDelay:       20 * time.Millisecond,
+		MinSamples:         10,
+		MaxHedgesPerSecond: 5,
+	})
+	svc := services.NewVectorMemoryServiceHedger(mockVector, h)
+
+	start := time.Now()
+	resp, err := svc.GetContext(context.Background(), "chat1")
+	elapsed := time.Since(start)
+
+	assert.NoError(t, err)
+	assert.Same(t, fastResp, resp)
+	assert.Less(t, elapsed, 100*time.Millisecond)
+	mockVector.AssertExpectations(t)
+}
@@ -0,0 +1,55 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vwency/resilient-scatter-gather/internal/requestid"
+	"github.com/vwency/resilient-scatter-gather/internal/services"
+	pb_permissions "github.com/vwency/resilient-scatter-gather/proto/permissions"
+	pb_user "github.com/vwency/resilient-scatter-gather/proto/user"
+	pb_vector "github.com/vwency/resilient-scatter-gather/proto/vector"
+	"google.golang.org/grpc/metadata"
+)
+
+func outgoingRequestID(ctx context.Context) []string {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	return md.Get(requestid.MetadataKey)
+}
+
+func TestServiceClients_ForwardRequestIDInMetadata(t *testing.T) {
+	ctx := requestid.NewContext(context.Background(), "req-abc")
+
+	var userIDs, permissionsIDs, vectorIDs []string
+
+	user := services.NewUserServiceClient(&fakeUserClient{
+		getUser: func(ctx context.Context, in *pb_user.GetUserRequest) (*pb_user.GetUserResponse, error) {
+			userIDs = outgoingRequestID(ctx)
+			return &pb_user.GetUserResponse{}, nil
+		},
+	})
+	permissions := services.NewPermissionsServiceClient(&fakePermissionsClient{
+		checkAccess: func(ctx context.Context, in *pb_permissions.CheckAccessRequest) (*pb_permissions.CheckAccessResponse, error) {
+			permissionsIDs = outgoingRequestID(ctx)
+			return &pb_permissions.CheckAccessResponse{}, nil
+		},
+	})
+	vector := services.NewVectorMemoryServiceClient(&fakeVectorClient{
+		getContext: func(ctx context.Context, in *pb_vector.GetContextRequest) (*pb_vector.GetContextResponse, error) {
+			vectorIDs = outgoingRequestID(ctx)
+			return &pb_vector.GetContextResponse{}, nil
+		},
+	})
+
+	_, err := user.GetUser(ctx, "user123")
+	assert.NoError(t, err)
+	_, err = permissions.CheckAccess(ctx, "user123", "chat1")
+	assert.NoError(t, err)
+	_, err = vector.GetContext(ctx, "chat1")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"req-abc"}, userIDs)
+	assert.Equal(t, []string{"req-abc"}, permissionsIDs)
+	assert.Equal(t, []string{"req-abc"}, vectorIDs)
+}
@@ -0,0 +1,41 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/vwency/resilient-scatter-gather/internal/retry"
+	"github.com/vwency/resilient-scatter-gather/internal/services"
+	pb_user "github.com/vwency/resilient-scatter-gather/proto/user"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUserServiceRetrier_TransientUnavailable_RecoversWithinSLA(t *testing.T) {
+	mockUser := new(UserService)
+
+	userResp := &pb_user.GetUserResponse{UserId: "user123"}
+	mockUser.On("GetUser", mock.Anything, "user123").Return(nil, status.Error(codes.Unavailable, "connection reset")).Once()
+	mockUser.On("GetUser", mock.Anything, "user123").Return(userResp, nil).Once()
+
+	r := retry.New("user", retry.Policy{
+		MaxAttempts:    3,
+		InitialBackoff: 2 * time.Millisecond,
+		Multiplier:     2,
+		MinAttemptTime: 5 * time.Millisecond,
+		RetryableCodes: []codes.Code{codes.Unavailable},
+	}, retry.NewBudget(0.1, 10))
+	svc := services.NewUserServiceRetrier(mockUser, r)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	resp, err := svc.GetUser(ctx, "user123")
+
+	assert.NoError(t, err)
+	assert.Same(t, userResp, resp)
+	mockUser.AssertExpectations(t)
+}
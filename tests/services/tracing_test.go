@@ -0,0 +1,181 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vwency/resilient-scatter-gather/internal/services"
+	pb_permissions "github.com/vwency/resilient-scatter-gather/proto/permissions"
+	pb_user "github.com/vwency/resilient-scatter-gather/proto/user"
+	pb_vector "github.com/vwency/resilient-scatter-gather/proto/vector"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type fakeUserClient struct {
+	getUser       func(ctx context.Context, in *pb_user.GetUserRequest) (*pb_user.GetUserResponse, error)
+	batchGetUsers func(ctx context.Context, in *pb_user.BatchGetUsersRequest) (*pb_user.BatchGetUsersResponse, error)
+}
+
+func (f *fakeUserClient) GetUser(ctx context.Context, in *pb_user.GetUserRequest, opts ...grpc.CallOption) (*pb_user.GetUserResponse, error) {
+	return f.getUser(ctx, in)
+}
+
+func (f *fakeUserClient) BatchGetUsers(ctx context.Context, in *pb_user.BatchGetUsersRequest, opts ...grpc.CallOption) (*pb_user.BatchGetUsersResponse, error) {
+	return f.batchGetUsers(ctx, in)
+}
+
+type fakePermissionsClient struct {
+	checkAccess      func(ctx context.Context, in *pb_permissions.CheckAccessRequest) (*pb_permissions.CheckAccessResponse, error)
+	batchCheckAccess func(ctx context.Context, in *pb_permissions.BatchCheckAccessRequest) (*pb_permissions.BatchCheckAccessResponse, error)
+}
+
+func (f *fakePermissionsClient) CheckAccess(ctx context.Context, in *pb_permissions.CheckAccessRequest, opts ...grpc.CallOption) (*pb_permissions.CheckAccessResponse, error) {
+	return f.checkAccess(ctx, in)
+}
+
+func (f *fakePermissionsClient) BatchCheckAccess(ctx context.Context, in *pb_permissions.BatchCheckAccessRequest, opts ...grpc.CallOption) (*pb_permissions.BatchCheckAccessResponse, error) {
+	return f.batchCheckAccess(ctx, in)
+}
+
+// startTracedRequest returns a context carrying a recording parent span
+// and the exporter its children are written to.
+func startTracedRequest(t *testing.T) (context.Context, trace.Span, *tracetest.InMemoryExporter) {
+	t.Helper()
+
+	previous := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(previous) })
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+
+	ctx, span := tp.Tracer("test").Start(context.Background(), "request")
+	return ctx, span, exporter
+}
+
+func outgoingTraceparent(ctx context.Context) string {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	if values := md.Get("traceparent"); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func TestServiceClients_PropagateTraceContextInMetadata(t *testing.T) {
+	tests := []struct {
+		name string
+		span string
+		call func(ctx context.Context, seen *string) error
+	}{
+		{
+			name: "user",
+			span: "UserService/GetUser",
+			call: func(ctx context.Context, seen *string) error {
+				client := services.NewUserServiceClient(&fakeUserClient{
+					getUser: func(ctx context.Context, in *pb_user.GetUserRequest) (*pb_user.GetUserResponse, error) {
+						*seen = outgoingTraceparent(ctx)
+						return &pb_user.GetUserResponse{}, nil
+					},
+				})
+				_, err := client.GetUser(ctx, "user123")
+				return err
+			},
+		},
+		{
+			name: "permissions",
+			span: "PermissionsService/CheckAccess",
+			call: func(ctx context.Context, seen *string) error {
+				client := services.NewPermissionsServiceClient(&fakePermissionsClient{
+					checkAccess: func(ctx context.Context, in *pb_permissions.CheckAccessRequest) (*pb_permissions.CheckAccessResponse, error) {
+						*seen = outgoingTraceparent(ctx)
+						return &pb_permissions.CheckAccessResponse{}, nil
+					},
+				})
+				_, err := client.CheckAccess(ctx, "user123", "chat1")
+				return err
+			},
+		},
+		{
+			name: "vector",
+			span: "VectorMemoryService/GetContext",
+			call: func(ctx context.Context, seen *string) error {
+				client := services.NewVectorMemoryServiceClient(&fakeVectorClient{
+					getContext: func(ctx context.Context, in *pb_vector.GetContextRequest) (*pb_vector.GetContextResponse, error) {
+						*seen = outgoingTraceparent(ctx)
+						return &pb_vector.GetContextResponse{}, nil
+					},
+				})
+				_, err := client.GetContext(ctx, "chat1")
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, parent, exporter := startTracedRequest(t)
+
+			var traceparent string
+			err := tt.call(ctx, &traceparent)
+			parent.End()
+
+			require.NoError(t, err)
+
+			spans := exporter.GetSpans()
+			require.Len(t, spans, 2)
+			client := spans[0]
+			assert.Equal(t, tt.span, client.Name)
+			assert.Equal(t, trace.SpanKindClient, client.SpanKind)
+			assert.Equal(t, parent.SpanContext().SpanID(), client.Parent.SpanID())
+
+			expected := "00-" + client.SpanContext.TraceID().String() + "-" + client.SpanContext.SpanID().String() + "-01"
+			assert.Equal(t, expected, traceparent)
+		})
+	}
+}
+
+func TestUserServiceClient_Error_MarksClientSpan(t *testing.T) {
+	ctx, parent, exporter := startTracedRequest(t)
+
+	client := services.NewUserServiceClient(&fakeUserClient{
+		getUser: func(ctx context.Context, in *pb_user.GetUserRequest) (*pb_user.GetUserResponse, error) {
+			return nil, status.Error(codes.Unavailable, "connection refused")
+		},
+	})
+
+	_, err := client.GetUser(ctx, "user123")
+	parent.End()
+
+	assert.ErrorIs(t, err, services.ErrUnavailable)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "UserService/GetUser", spans[0].Name)
+	assert.Equal(t, "Error", spans[0].Status.Code.String())
+}
+
+func TestUserServiceClient_WithoutParentSpan_SendsNoTraceparent(t *testing.T) {
+	var traceparent string
+	client := services.NewUserServiceClient(&fakeUserClient{
+		getUser: func(ctx context.Context, in *pb_user.GetUserRequest) (*pb_user.GetUserResponse, error) {
+			traceparent = outgoingTraceparent(ctx)
+			return &pb_user.GetUserResponse{}, nil
+		},
+	})
+
+	_, err := client.GetUser(context.Background(), "user123")
+
+	assert.NoError(t, err)
+	assert.Empty(t, traceparent)
+}
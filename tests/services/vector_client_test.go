@@ -0,0 +1,93 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vwency/resilient-scatter-gather/internal/services"
+	pb_vector "github.com/vwency/resilient-scatter-gather/proto/vector"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type fakeVectorClient struct {
+	getContext      func(ctx context.Context, in *pb_vector.GetContextRequest) (*pb_vector.GetContextResponse, error)
+	batchGetContext func(ctx context.Context, in *pb_vector.BatchGetContextRequest) (*pb_vector.BatchGetContextResponse, error)
+}
+
+func (f *fakeVectorClient) GetContext(ctx context.Context, in *pb_vector.GetContextRequest, opts ...grpc.CallOption) (*pb_vector.GetContextResponse, error) {
+	return f.getContext(ctx, in)
+}
+
+func (f *fakeVectorClient) BatchGetContext(ctx context.Context, in *pb_vector.BatchGetContextRequest, opts ...grpc.CallOption) (*pb_vector.BatchGetContextResponse, error) {
+	return f.batchGetContext(ctx, in)
+}
+
+func TestVectorMemoryServiceClient_BackendErrors_AreTyped(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		target  error
+		code    codes.Code
+		service string
+	}{
+		{name: "unavailable", err: status.Error(codes.Unavailable, "connection refused"), target: services.ErrUnavailable, code: codes.Unavailable},
+		{name: "invalid argument", err: status.Error(codes.InvalidArgument, "bad chat id"), target: services.ErrInvalidArgument, code: codes.InvalidArgument},
+		{name: "deadline", err: status.Error(codes.DeadlineExceeded, "deadline exceeded"), target: services.ErrTimeout, code: codes.DeadlineExceeded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := services.NewVectorMemoryServiceClient(&fakeVectorClient{
+				getContext: func(ctx context.Context, in *pb_vector.GetContextRequest) (*pb_vector.GetContextResponse, error) {
+					return nil, tt.err
+				},
+			})
+
+			resp, err := client.GetContext(context.Background(), "chat1")
+
+			assert.Nil(t, resp)
+			assert.ErrorIs(t, err, tt.target)
+			assert.Equal(t, tt.code, status.Code(err))
+
+			var backendErr *services.BackendError
+			assert.ErrorAs(t, err, &backendErr)
+			assert.Equal(t, "VectorMemoryService", backendErr.Service)
+		})
+	}
+}
+
+func TestVectorMemoryServiceClient_Deadline_ReturnsErrTimeout(t *testing.T) {
+	client := services.NewVectorMemoryServiceClient(&fakeVectorClient{
+		getContext: func(ctx context.Context, in *pb_vector.GetContextRequest) (*pb_vector.GetContextResponse, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := client.GetContext(ctx, "chat1")
+
+	assert.ErrorIs(t, err, services.ErrTimeout)
+	assert.NotErrorIs(t, err, services.ErrUnavailable)
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+}
+
+func TestVectorMemoryServiceClient_Success_ReturnsResponse(t *testing.T) {
+	vectorResp := &pb_vector.GetContextResponse{TotalCount: 3}
+	client := services.NewVectorMemoryServiceClient(&fakeVectorClient{
+		getContext: func(ctx context.Context, in *pb_vector.GetContextRequest) (*pb_vector.GetContextResponse, error) {
+			assert.Equal(t, "chat1", in.ChatId)
+			return vectorResp, nil
+		},
+	})
+
+	resp, err := client.GetContext(context.Background(), "chat1")
+
+	assert.NoError(t, err)
+	assert.Same(t, vectorResp, resp)
+}
@@ -0,0 +1,158 @@
+package simulator_test
+
+import (
+	"context"
+	"math/rand/v2"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vwency/resilient-scatter-gather/internal/backendstub"
+	"github.com/vwency/resilient-scatter-gather/internal/services"
+	"github.com/vwency/resilient-scatter-gather/internal/simulator"
+	pb_user "github.com/vwency/resilient-scatter-gather/proto/user"
+	"github.com/vwency/resilient-scatter-gather/tests/testclock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+func newInjector(settings simulator.Settings, clock *testclock.Clock) *simulator.Injector {
+	return simulator.NewWithSource(settings, clock.Now, rand.New(rand.NewPCG(1, 2)))
+}
+
+func TestPlan_UnknownMethod_PassesThrough(t *testing.T) {
+	injector := newInjector(simulator.Settings{}, testclock.New())
+
+	delay, err := injector.Plan("GetUser")
+
+	assert.Zero(t, delay)
+	assert.NoError(t, err)
+}
+
+func TestPlan_Distributions_StayWithinBounds(t *testing.T) {
+	tests := []struct {
+		name    string
+		latency simulator.Latency
+	}{
+		{name: "uniform", latency: simulator.Latency{Distribution: simulator.Uniform, Min: 5 * time.Millisecond, Max: 10 * time.Millisecond}},
+		{name: "normal", latency: simulator.Latency{Distribution: simulator.Normal, Mean: 8 * time.Millisecond, StdDev: 5 * time.Millisecond, Min: 5 * time.Millisecond, Max: 10 * time.Millisecond}},
+		{name: "exponential", latency: simulator.Latency{Distribution: simulator.Exponential, Mean: 3 * time.Millisecond, Min: 5 * time.Millisecond, Max: 10 * time.Millisecond}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			injector := newInjector(simulator.Settings{
+				Methods: map[string]simulator.Method{"GetUser": {Latency: tt.latency}},
+			}, testclock.New())
+
+			for i := 0; i < 1000; i++ {
+				delay, err := injector.Plan("GetUser")
+				require.NoError(t, err)
+				assert.GreaterOrEqual(t, delay, 5*time.Millisecond)
+				assert.LessOrEqual(t, delay, 10*time.Millisecond)
+			}
+		})
+	}
+}
+
+func TestPlan_ConstantLatency_ReturnsMean(t *testing.T) {
+	injector := newInjector(simulator.Settings{
+		Methods: map[string]simulator.Method{"getuser": {Latency: simulator.Latency{Mean: 7 * time.Millisecond}}},
+	}, testclock.New())
+
+	delay, err := injector.Plan("GetUser")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 7*time.Millisecond, delay)
+}
+
+func TestPlan_ErrorRate_FailsRoughlyThatShareWithCode(t *testing.T) {
+	injector := newInjector(simulator.Settings{
+		Methods: map[string]simulator.Method{"CheckAccess": {ErrorRate: 0.3, ErrorCode: codes.ResourceExhausted}},
+	}, testclock.New())
+
+	failures := 0
+	for i := 0; i < 2000; i++ {
+		if _, err := injector.Plan("CheckAccess"); err != nil {
+			assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+			failures++
+		}
+	}
+
+	assert.InDelta(t, 600, failures, 100)
+}
+
+func TestPlan_OutageWindow_RepeatsEveryPeriod(t *testing.T) {
+	clock := testclock.New()
+	injector := newInjector(simulator.Settings{
+		Outages: []simulator.Outage{{Start: 10 * time.Second, Duration: 2 * time.Second, Every: 30 * time.Second, Code: codes.Unavailable}},
+	}, clock)
+
+	steps := []struct {
+		at   time.Duration
+		down bool
+	}{
+		{at: 5 * time.Second, down: false},
+		{at: 10 * time.Second, down: true},
+		{at: 11 * time.Second, down: true},
+		{at: 12 * time.Second, down: false},
+		{at: 41 * time.Second, down: true},
+		{at: 43 * time.Second, down: false},
+	}
+
+	elapsed := time.Duration(0)
+	for _, step := range steps {
+		clock.Advance(step.at - elapsed)
+		elapsed = step.at
+		_, err := injector.Plan("GetUser")
+		if step.down {
+			assert.Equal(t, codes.Unavailable, status.Code(err), step.at)
+		} else {
+			assert.NoError(t, err, step.at)
+		}
+	}
+}
+
+func TestUnaryServerInterceptor_InjectsLatencyAndSparesHealth(t *testing.T) {
+	clock := testclock.New()
+	injector := newInjector(simulator.Settings{
+		Methods: map[string]simulator.Method{"GetUser": {Latency: simulator.Latency{Mean: 50 * time.Millisecond}}},
+		Outages: []simulator.Outage{{Start: time.Hour, Duration: time.Hour, Code: codes.Unavailable}},
+	}, clock)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := backendstub.NewServer(&pb_user.UserService_ServiceDesc, services.NewUserServiceServer(),
+		grpc.UnaryInterceptor(injector.UnaryServerInterceptor()))
+	go func() { _ = server.Serve(lis) }()
+	defer server.Stop()
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := pb_user.NewUserServiceClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	_, err = client.GetUser(ctx, &pb_user.GetUserRequest{UserId: "user123"})
+	cancel()
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+
+	start := time.Now()
+	resp, err := client.GetUser(context.Background(), &pb_user.GetUserRequest{UserId: "user123"})
+	require.NoError(t, err)
+	assert.Equal(t, "user123", resp.GetUserId())
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	clock.Advance(90 * time.Minute)
+	_, err = client.GetUser(context.Background(), &pb_user.GetUserRequest{UserId: "user123"})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	healthResp, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, healthResp.GetStatus())
+}
@@ -0,0 +1,30 @@
+package testclock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is a clock for tests that only moves when told to. Its Now can be
+// passed wherever a constructor takes a func() time.Time.
+type Clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// New returns a clock stopped at the current time.
+func New() *Clock {
+	return &Clock{now: time.Now()}
+}
+
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
@@ -0,0 +1,339 @@
+package tlsconfig_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log/slog"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vwency/resilient-scatter-gather/internal/tlsconfig"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+type authority struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newAuthority(t *testing.T, name string) *authority {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &authority{
+		cert: cert,
+		key:  key,
+		pem:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}
+}
+
+// issue returns a PEM certificate and key signed by a, valid for dnsNames
+// and 127.0.0.1.
+func (a *authority) issue(t *testing.T, usage x509.ExtKeyUsage, dnsNames ...string) ([]byte, []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "leaf"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		DNSNames:     dnsNames,
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, a.cert, &key.PublicKey, a.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// startServer serves the gRPC health service over TLS with a certificate
+// for dnsNames. With clientCAs set, clients must present a certificate
+// signed by it.
+func startServer(t *testing.T, serverCA *authority, clientCAs *authority, dnsNames ...string) string {
+	t.Helper()
+
+	certPEM, keyPEM := serverCA.issue(t, x509.ExtKeyUsageServerAuth, dnsNames...)
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+
+	config := &tls.Config{Certificates: []tls.Certificate{cert}}
+	if clientCAs != nil {
+		pool := x509.NewCertPool()
+		pool.AddCert(clientCAs.cert)
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer(grpc.Creds(credentials.NewTLS(config)))
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+
+	return lis.Addr().String()
+}
+
+func writeFile(t *testing.T, dir, name string, data []byte) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+// check makes one health check over a fresh connection, so that every call
+// goes through a new handshake.
+func check(t *testing.T, addr string, creds credentials.TransportCredentials) error {
+	t.Helper()
+
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	return err
+}
+
+func TestReloader_MutualTLS_Connects(t *testing.T) {
+	ca := newAuthority(t, "ca")
+	addr := startServer(t, ca, ca, "localhost")
+
+	dir := t.TempDir()
+	certPEM, keyPEM := ca.issue(t, x509.ExtKeyUsageClientAuth)
+	reloader, err := tlsconfig.New(tlsconfig.Settings{
+		CAFile:   writeFile(t, dir, "ca.pem", ca.pem),
+		CertFile: writeFile(t, dir, "client.pem", certPEM),
+		KeyFile:  writeFile(t, dir, "client-key.pem", keyPEM),
+	})
+	require.NoError(t, err)
+
+	assert.NoError(t, check(t, addr, reloader.Credentials()))
+}
+
+func TestReloader_WithoutClientCertificate_IsRejectedByMutualTLSServer(t *testing.T) {
+	ca := newAuthority(t, "ca")
+	addr := startServer(t, ca, ca, "localhost")
+
+	reloader, err := tlsconfig.New(tlsconfig.Settings{
+		CAFile: writeFile(t, t.TempDir(), "ca.pem", ca.pem),
+	})
+	require.NoError(t, err)
+
+	assert.Error(t, check(t, addr, reloader.Credentials()))
+}
+
+func TestReloader_UnknownServerCA_IsRejected(t *testing.T) {
+	addr := startServer(t, newAuthority(t, "server-ca"), nil, "localhost")
+
+	reloader, err := tlsconfig.New(tlsconfig.Settings{
+		CAFile: writeFile(t, t.TempDir(), "ca.pem", newAuthority(t, "other-ca").pem),
+	})
+	require.NoError(t, err)
+
+	assert.Error(t, check(t, addr, reloader.Credentials()))
+}
+
+func TestReloader_ServerNameOverride(t *testing.T) {
+	ca := newAuthority(t, "ca")
+	addr := startServer(t, ca, nil, "backend.internal")
+	// Dial by a name the certificate does not cover.
+	_, port, err := net.SplitHostPort(addr)
+	require.NoError(t, err)
+	addr = net.JoinHostPort("localhost", port)
+
+	caFile := writeFile(t, t.TempDir(), "ca.pem", ca.pem)
+
+	plain, err := tlsconfig.New(tlsconfig.Settings{CAFile: caFile})
+	require.NoError(t, err)
+	assert.Error(t, check(t, addr, plain.Credentials()))
+
+	overridden, err := tlsconfig.New(tlsconfig.Settings{CAFile: caFile, ServerName: "backend.internal"})
+	require.NoError(t, err)
+	assert.NoError(t, check(t, addr, overridden.Credentials()))
+}
+
+func TestReloader_MinVersion_IsEnforced(t *testing.T) {
+	ca := newAuthority(t, "ca")
+	addr := startServer(t, ca, nil, "localhost")
+
+	reloader, err := tlsconfig.New(tlsconfig.Settings{
+		CAFile:     writeFile(t, t.TempDir(), "ca.pem", ca.pem),
+		MinVersion: tls.VersionTLS13,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, uint16(tls.VersionTLS13), reloader.Config().MinVersion)
+	assert.NoError(t, check(t, addr, reloader.Credentials()))
+}
+
+func TestReloader_Reload_PicksUpRotatedClientCertificate(t *testing.T) {
+	oldCA := newAuthority(t, "old-ca")
+	newCA := newAuthority(t, "new-ca")
+	serverCA := newAuthority(t, "server-ca")
+	addr := startServer(t, serverCA, newCA, "localhost")
+
+	dir := t.TempDir()
+	certPEM, keyPEM := oldCA.issue(t, x509.ExtKeyUsageClientAuth)
+	certFile := writeFile(t, dir, "client.pem", certPEM)
+	keyFile := writeFile(t, dir, "client-key.pem", keyPEM)
+	reloader, err := tlsconfig.New(tlsconfig.Settings{
+		CAFile:   writeFile(t, dir, "ca.pem", serverCA.pem),
+		CertFile: certFile,
+		KeyFile:  keyFile,
+	})
+	require.NoError(t, err)
+	creds := reloader.Credentials()
+
+	require.Error(t, check(t, addr, creds))
+
+	reloaded, err := reloader.Reload()
+	require.NoError(t, err)
+	assert.False(t, reloaded, "unchanged files must not rebuild the configuration")
+
+	certPEM, keyPEM = newCA.issue(t, x509.ExtKeyUsageClientAuth)
+	writeFile(t, dir, "client.pem", certPEM)
+	writeFile(t, dir, "client-key.pem", keyPEM)
+
+	reloaded, err = reloader.Reload()
+	require.NoError(t, err)
+	assert.True(t, reloaded)
+	assert.NoError(t, check(t, addr, creds))
+}
+
+func TestReloader_Reload_MismatchedKeyKeepsPreviousCertificate(t *testing.T) {
+	ca := newAuthority(t, "ca")
+	addr := startServer(t, ca, ca, "localhost")
+
+	dir := t.TempDir()
+	certPEM, keyPEM := ca.issue(t, x509.ExtKeyUsageClientAuth)
+	reloader, err := tlsconfig.New(tlsconfig.Settings{
+		CAFile:   writeFile(t, dir, "ca.pem", ca.pem),
+		CertFile: writeFile(t, dir, "client.pem", certPEM),
+		KeyFile:  writeFile(t, dir, "client-key.pem", keyPEM),
+	})
+	require.NoError(t, err)
+
+	// Rotate only the certificate, as a half-finished rotation would.
+	rotated, _ := ca.issue(t, x509.ExtKeyUsageClientAuth)
+	writeFile(t, dir, "client.pem", rotated)
+
+	_, err = reloader.Reload()
+	assert.Error(t, err)
+	assert.NoError(t, check(t, addr, reloader.Credentials()))
+}
+
+func TestReloader_Watch_ReloadsOnRotation(t *testing.T) {
+	oldCA := newAuthority(t, "old-ca")
+	newCA := newAuthority(t, "new-ca")
+	addr := startServer(t, newCA, nil, "localhost")
+
+	dir := t.TempDir()
+	caFile := writeFile(t, dir, "ca.pem", oldCA.pem)
+	reloader, err := tlsconfig.New(tlsconfig.Settings{CAFile: caFile})
+	require.NoError(t, err)
+	creds := reloader.Credentials()
+	require.Error(t, check(t, addr, creds))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go reloader.Watch(ctx, 10*time.Millisecond, discardLogger())
+
+	writeFile(t, dir, "ca.pem", newCA.pem)
+
+	assert.Eventually(t, func() bool {
+		return check(t, addr, creds) == nil
+	}, 2*time.Second, 20*time.Millisecond)
+}
+
+func TestNew_RejectsCertificateWithoutKey(t *testing.T) {
+	ca := newAuthority(t, "ca")
+	certPEM, _ := ca.issue(t, x509.ExtKeyUsageClientAuth)
+
+	_, err := tlsconfig.New(tlsconfig.Settings{
+		CertFile: writeFile(t, t.TempDir(), "client.pem", certPEM),
+	})
+
+	assert.Error(t, err)
+}
+
+func TestNew_RejectsCAFileWithoutCertificates(t *testing.T) {
+	_, err := tlsconfig.New(tlsconfig.Settings{
+		CAFile: writeFile(t, t.TempDir(), "ca.pem", []byte("not a certificate")),
+	})
+
+	assert.ErrorContains(t, err, "no certificates found")
+}
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    uint16
+		wantErr bool
+	}{
+		{in: "", want: tls.VersionTLS12},
+		{in: "1.2", want: tls.VersionTLS12},
+		{in: "1.3", want: tls.VersionTLS13},
+		{in: "TLS13", wantErr: true},
+		{in: "1.0", wantErr: true},
+		{in: "1.1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := tlsconfig.ParseVersion(tt.in)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}